@@ -6,10 +6,10 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
-	"testing"
+	"io/fs"
 	"text/template"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Assert compares the actual data received with the expected data in the
@@ -19,47 +19,75 @@ import (
 // `name` refers to the name of the test, and it should typically be unique
 // within the package. Also, it should be a valid file name (so keeping to
 // `a-z0-9\-\_` is a good idea).
-func (g *Goldie) Assert(t *testing.T, name string, actualData []byte) {
+func (g *Goldie) Assert(t TB, name string, actualData []byte) {
 	t.Helper()
-	if *update {
-		err := g.Update(t, name, actualData)
-		if err != nil {
-			t.Error(err)
-			t.FailNow()
+	result, err := g.Check(t, name, actualData)
+	g.report(t, result, err)
+}
+
+// Check compares the actual data received with the expected data in the
+// golden files, just like Assert, but without failing the test. Instead the
+// outcome of the comparison is returned as a Result. If the update flag is
+// set, it will also update the golden file.
+//
+// The returned error is only set when the comparison could not be carried
+// out, e.g. if the golden file could not be read or written. A missing or
+// mismatching golden file is reported through Result.Status.
+//
+// Any scrubbers added with WithScrubber are applied to the actual data
+// first.
+func (g *Goldie) Check(t TB, name string, actualData []byte) (*Result, error) {
+	actualData = g.scrub(actualData, nil)
+
+	if err := g.flagError(); err != nil {
+		return nil, fatal(err)
+	}
+
+	used.watch(t)
+	if err := used.assert(g.fsys, g.fixtureKey(t, name), t.Name(), actualData); err != nil {
+		return nil, fatal(err)
+	}
+
+	doUpdate := g.shouldUpdate(t, name)
+	if doUpdate {
+		if err := g.Update(t, name, actualData); err != nil {
+			return nil, fatal(err)
 		}
 	}
 
-	err := g.compare(t, name, actualData)
+	result, err := g.check(t, name, actualData)
 	if err != nil {
-		{
-			var e *errFixtureNotFound
-			if errors.As(err, &e) {
-				t.Error(err)
-				t.FailNow()
-				return
-			}
-		}
+		return nil, err
+	}
 
-		{
-			var e *errFixtureMismatch
-			if errors.As(err, &e) {
-				t.Error(err)
-				return
-			}
-		}
+	// The updated golden file may still not match, e.g. if the EqualFn
+	// rejects the written data.
+	if doUpdate && result.Status == StatusMatch {
+		result.Status = StatusUpdated
+	}
 
-		t.Error(err)
+	if *received || g.useReceivedFiles {
+		if err := g.updateReceived(result); err != nil {
+			return nil, fatal(err)
+		}
 	}
+
+	return result, nil
 }
 
 // AssertJson compares the actual json data received with expected data in the
 // golden files. If the update flag is set, it will also update the golden
 // file.
 //
+// Fields selected by WithIgnorePaths are excluded from the comparison and
+// replaced with a placeholder in the golden file, keeping the encoding of the
+// rest of the data. An EqualFn set with WithEqualFn compares the data with
+// the ignored fields masked.
+//
 // `name` refers to the name of the test and it should typically be unique
 // within the package. Also it should be a valid file name (so keeping to
 // `a-z0-9\-\_` is a good idea).
-func (g *Goldie) AssertJson(t *testing.T, name string, actualJsonData interface{}) {
+func (g *Goldie) AssertJson(t TB, name string, actualJsonData interface{}) {
 	t.Helper()
 	js, err := json.MarshalIndent(actualJsonData, "", "  ")
 
@@ -68,17 +96,57 @@ func (g *Goldie) AssertJson(t *testing.T, name string, actualJsonData interface{
 		t.FailNow()
 	}
 
+	if len(g.ignoreJsonPaths) > 0 {
+		js, err = g.maskJsonText(js)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		g.withComparison(g.maskedJsonTextEqual).Assert(t, name, normalizeLF(js))
+		return
+	}
+
 	g.Assert(t, name, normalizeLF(js))
 }
 
+// AssertJsonBytes compares the actual raw JSON data received with expected
+// data in the golden files. The comparison is structural, i.e. object key
+// order and whitespace don't matter and numbers are compared numerically. If
+// the update flag is set, the golden file is written in a normalized,
+// pretty-printed form with sorted object keys.
+//
+// Fields selected by WithIgnorePaths are excluded from the comparison. An
+// EqualFn set with WithEqualFn replaces the structural comparison and is
+// given the normalized documents with the ignored fields masked.
+//
+// `name` refers to the name of the test and it should typically be unique
+// within the package. Also it should be a valid file name (so keeping to
+// `a-z0-9\-\_` is a good idea).
+func (g *Goldie) AssertJsonBytes(t TB, name string, actualJson []byte) {
+	t.Helper()
+	js, err := g.maskJson(actualJson)
+
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	g.withComparison(g.maskedJsonEqual).Assert(t, name, js)
+}
+
 // AssertXml compares the actual xml data received with expected data in the
 // golden files. If the update flag is set, it will also update the golden
 // file.
 //
+// Elements and attributes selected by WithIgnorePaths are excluded from the
+// comparison. An EqualFn set with WithEqualFn compares the data with the
+// ignored elements and attributes masked.
+//
 // `name` refers to the name of the test and it should typically be unique
 // within the package. Also it should be a valid file name (so keeping to
 // `a-z0-9\-\_` is a good idea).
-func (g *Goldie) AssertXml(t *testing.T, name string, actualXmlData interface{}) {
+func (g *Goldie) AssertXml(t TB, name string, actualXmlData interface{}) {
 	t.Helper()
 	x, err := xml.MarshalIndent(actualXmlData, "", "  ")
 
@@ -87,9 +155,75 @@ func (g *Goldie) AssertXml(t *testing.T, name string, actualXmlData interface{})
 		t.FailNow()
 	}
 
+	// Scrub with escaped tokens, keeping the document well-formed.
+	x = g.scrub(x, xmlEscape)
+
+	if len(g.ignoreXmlPaths) > 0 {
+		x, err = g.maskXml(x)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+
+		g.withComparison(g.maskedXmlEqual).Assert(t, name, normalizeLF(x))
+		return
+	}
+
 	g.Assert(t, name, normalizeLF(x))
 }
 
+// AssertYaml compares the actual data received, marshalled to YAML, with
+// expected data in the golden files. If the update flag is set, it will also
+// update the golden file.
+//
+// The data is converted through encoding/json, so `json` struct tags apply.
+// The golden file is written as canonical YAML with sorted keys and
+// consistent indentation, and compared semantically, just like
+// AssertYamlBytes does, unless an EqualFn is set with WithEqualFn.
+//
+// `name` refers to the name of the test and it should typically be unique
+// within the package. Also it should be a valid file name (so keeping to
+// `a-z0-9\-\_` is a good idea).
+func (g *Goldie) AssertYaml(t TB, name string, actualYamlData interface{}) {
+	t.Helper()
+	var actualYaml []byte
+	v, err := yamlValue(actualYamlData)
+	if err == nil {
+		actualYaml, err = encodeYaml([]*yaml.Node{v})
+	}
+
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	g.withDefaultComparison(yamlEqualFn).Assert(t, name, actualYaml)
+}
+
+// AssertYamlBytes compares the actual raw YAML data received with expected
+// data in the golden files. The comparison is semantic, i.e. it does not
+// depend on key order, quoting style, indentation or anchors, unless an
+// EqualFn is set with WithEqualFn. If the update flag is set, the golden file
+// is written as canonical YAML with sorted keys and consistent indentation.
+//
+// `name` refers to the name of the test and it should typically be unique
+// within the package. Also it should be a valid file name (so keeping to
+// `a-z0-9\-\_` is a good idea).
+func (g *Goldie) AssertYamlBytes(t TB, name string, actualYaml []byte) {
+	t.Helper()
+	docs, err := decodeYaml(actualYaml)
+	if err == nil {
+		actualYaml, err = encodeYaml(docs)
+	}
+
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	g.withDefaultComparison(yamlEqualFn).Assert(t, name, actualYaml)
+}
+
 // normalizeLF normalizes line feed character set across os (es)
 // \r\n (windows) & \r (mac) into \n (unix)
 func normalizeLF(d []byte) []byte {
@@ -110,9 +244,30 @@ func normalizeLF(d []byte) []byte {
 // the name of the test and it should typically be unique within the package.
 // Also it should be a valid file name (so keeping to `a-z0-9\-\_` is a good
 // idea).
-func (g *Goldie) AssertWithTemplate(t *testing.T, name string, data interface{}, actualData []byte) {
+func (g *Goldie) AssertWithTemplate(t TB, name string, data interface{}, actualData []byte) {
 	t.Helper()
-	if *update {
+	result, err := g.CheckWithTemplate(t, name, data, actualData)
+	g.report(t, result, err)
+}
+
+// CheckWithTemplate compares the actual data received with the expected data
+// in the golden files after executing it as a template with data parameter,
+// just like AssertWithTemplate, but without failing the test. See Check for
+// details on the returned Result and error.
+func (g *Goldie) CheckWithTemplate(t TB, name string, data interface{}, actualData []byte) (*Result, error) {
+	actualData = g.scrub(actualData, nil)
+
+	if err := g.flagError(); err != nil {
+		return nil, fatal(err)
+	}
+
+	used.watch(t)
+	if err := used.assert(g.fsys, g.fixtureKey(t, name), t.Name(), actualData); err != nil {
+		return nil, fatal(err)
+	}
+
+	doUpdate := g.shouldUpdate(t, name)
+	if doUpdate {
 		var err error
 		if *withTemplate {
 			err = g.UpdateWithTemplate(t, name, data, actualData)
@@ -120,75 +275,154 @@ func (g *Goldie) AssertWithTemplate(t *testing.T, name string, data interface{},
 			err = g.Update(t, name, actualData)
 		}
 		if err != nil {
-			t.Error(err)
-			t.FailNow()
+			return nil, fatal(err)
 		}
 	}
 
-	err := g.compareTemplate(t, name, data, actualData)
+	result, err := g.checkTemplate(t, name, data, actualData)
 	if err != nil {
-		{
-			var e *errFixtureNotFound
-			if errors.As(err, &e) {
-				t.Error(err)
-				t.FailNow()
-				return
-			}
+		return nil, err
+	}
+
+	// The updated golden file may still not match, e.g. if the EqualFn
+	// rejects the written data.
+	if doUpdate && result.Status == StatusMatch {
+		result.Status = StatusUpdated
+	}
+
+	if *received || g.useReceivedFiles {
+		if err := g.updateReceived(result); err != nil {
+			return nil, fatal(err)
 		}
+	}
 
-		{
-			var e *errFixtureMismatch
-			if errors.As(err, &e) {
-				t.Error(err)
-				return
-			}
+	return result, nil
+}
+
+// fatalError marks an error that stops the test when reported, like failing
+// to update the golden file. Errors of the comparison itself, like a missing
+// template key, are only reported.
+type fatalError struct {
+	err error
+}
+
+// fatal marks the error as stopping the test.
+func fatal(err error) error {
+	return &fatalError{err: err}
+}
+
+// Error returns the error message.
+func (e *fatalError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the marked error.
+func (e *fatalError) Unwrap() error {
+	return e.err
+}
+
+// report fails the test according to the outcome of a Check. Missing golden
+// files and fatal errors will stop the test, while mismatches and other
+// errors are only reported.
+func (g *Goldie) report(t TB, result *Result, err error) {
+	t.Helper()
+	if err != nil {
+		t.Error(err)
+
+		var e *fatalError
+		if errors.As(err, &e) {
+			t.FailNow()
 		}
+		return
+	}
+
+	err = result.err()
+	if err == nil {
+		return
+	}
 
+	var e *FixtureNotFoundError
+	if errors.As(err, &e) {
 		t.Error(err)
+		t.FailNow()
+		return
 	}
+
+	t.Error(err)
 }
 
 // compare is reading the golden fixture file and compare the stored data with
 // the actual data.
-func (g *Goldie) compare(t *testing.T, name string, actualData []byte) error {
-	expectedData, err := ioutil.ReadFile(g.GoldenFileName(t, name))
+func (g *Goldie) compare(t TB, name string, actualData []byte) error {
+	result, err := g.check(t, name, actualData)
+	if err != nil {
+		return err
+	}
+
+	return result.err()
+}
 
+// compareTemplate is reading the golden fixture file and compare the stored
+// data with the actual data.
+func (g *Goldie) compareTemplate(t TB, name string, data interface{}, actualData []byte) error {
+	result, err := g.checkTemplate(t, name, data, actualData)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return newErrFixtureNotFound()
-		}
+		return err
+	}
+
+	return result.err()
+}
 
-		return fmt.Errorf("expected %s to be nil", err.Error())
+// check is reading the golden fixture file and compares the stored data with
+// the actual data, returning the outcome as a Result.
+func (g *Goldie) check(t TB, name string, actualData []byte) (*Result, error) {
+	goldenFile, err := g.resolveGoldenFile(t, name)
+	if err != nil {
+		return nil, fatal(err)
 	}
 
-	if !g.equal(actualData, expectedData) {
-		msg := "Result did not match the golden fixture. Diff is below:\n\n"
-		actual := string(actualData)
-		expected := string(expectedData)
+	result := &Result{
+		GoldenFile: goldenFile,
+		TestName:   t.Name(),
+		Actual:     actualData,
+	}
 
-		if g.diffFn != nil {
-			msg += g.diffFn(actual, expected)
-		} else {
-			msg += Diff(g.diffEngine, actual, expected)
+	expectedData, err := g.readGolden(t, goldenFile, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			result.Status = StatusMissing
+			return result, nil
 		}
 
-		return newErrFixtureMismatch(msg)
+		return nil, fmt.Errorf("could not read golden file: %w", err)
 	}
 
-	return nil
+	g.match(result, expectedData)
+	return result, nil
 }
 
-// compareTemplate is reading the golden fixture file and compare the stored
-// data with the actual data.
-func (g *Goldie) compareTemplate(t *testing.T, name string, data interface{}, actualData []byte) error {
-	expectedDataTmpl, err := ioutil.ReadFile(g.GoldenFileName(t, name))
+// checkTemplate is reading the golden fixture file, executes it as a template
+// with data parameter and compares the result with the actual data.
+func (g *Goldie) checkTemplate(t TB, name string, data interface{}, actualData []byte) (*Result, error) {
+	goldenFile, err := g.resolveGoldenFile(t, name)
+	if err != nil {
+		return nil, fatal(err)
+	}
+
+	result := &Result{
+		GoldenFile: goldenFile,
+		TestName:   t.Name(),
+		Actual:     actualData,
+	}
 
+	expectedDataTmpl, err := g.readGolden(t, goldenFile, name)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return newErrFixtureNotFound()
+		if errors.Is(err, fs.ErrNotExist) {
+			result.Status = StatusMissing
+			return result, nil
 		}
 
-		return fmt.Errorf("expected %s to be nil", err.Error())
+		return nil, fmt.Errorf("could not read golden file: %w", err)
 	}
 
 	missingKey := "error"
@@ -198,30 +432,83 @@ func (g *Goldie) compareTemplate(t *testing.T, name string, data interface{}, ac
 
 	tmpl, err := template.New("test").Option("missingkey=" + missingKey).Parse(string(expectedDataTmpl))
 	if err != nil {
-		return fmt.Errorf("expected %s to be nil", err.Error())
+		return nil, fmt.Errorf("could not parse golden file %s as template: %w", goldenFile, err)
 	}
 
 	var expectedData bytes.Buffer
 	err = tmpl.Execute(&expectedData, data)
 	if err != nil {
-		return newErrMissingKey(fmt.Sprintf("Template error: %s", err.Error()))
+		return nil, newErrMissingKey(result.GoldenFile, result.TestName, err)
 	}
 
-	if !g.equal(actualData, expectedData.Bytes()) {
-		msg := "Result did not match the golden fixture. Diff is below:\n\n"
-		actual := string(actualData)
-		expected := expectedData.String()
+	g.match(result, expectedData.Bytes())
+	return result, nil
+}
 
-		if g.diffFn != nil {
-			msg += g.diffFn(actual, expected)
-		} else {
-			msg += Diff(g.diffEngine, actual, expected)
-		}
+// resolveGoldenFile returns the golden file to compare the actual data of the
+// fixture with. That's the most specific existing variant of the golden file
+// or, for snapshots, the snapshot file.
+func (g *Goldie) resolveGoldenFile(t TB, name string) (string, error) {
+	if g.snapshotMode != SnapshotOff {
+		return g.snapshotFile(t)
+	}
 
-		return newErrFixtureMismatch(msg)
+	goldenFile, err := g.goldenFileName(t, name)
+	if err != nil {
+		return "", err
+	}
+
+	return g.resolveVariant(goldenFile), nil
+}
+
+// readGolden reads the expected data of the fixture from the golden file, or
+// from its entry in the snapshot file.
+func (g *Goldie) readGolden(t TB, goldenFile, name string) ([]byte, error) {
+	if g.snapshotMode != SnapshotOff {
+		return g.readSnapshot(t, goldenFile, name)
+	}
+
+	used.track(g, goldenFile)
+	return g.readFile(goldenFile)
+}
+
+// match compares the expected data with the actual data of the result and
+// sets the status, and if needed, the diff accordingly.
+func (g *Goldie) match(result *Result, expectedData []byte) {
+	result.Expected = expectedData
+
+	if g.equal(result.Actual, expectedData) {
+		result.Status = StatusMatch
+		return
+	}
+
+	result.Status = StatusMismatch
+	actual := string(result.Actual)
+	expected := string(expectedData)
+
+	if g.diffFn != nil {
+		result.Diff = g.diffFn(actual, expected)
+	} else {
+		result.Diff = g.diffOptions.diff(g.diffEngine, actual, expected)
+	}
+}
+
+// withComparison returns a copy of the tester that uses fn to compare the
+// actual and expected data.
+func (g *Goldie) withComparison(fn EqualFn) *Goldie {
+	c := *g
+	c.equalFn = fn
+	return &c
+}
+
+// withDefaultComparison returns a copy of g using fn to compare golden
+// files, unless an EqualFn was set with WithEqualFn.
+func (g *Goldie) withDefaultComparison(fn EqualFn) *Goldie {
+	if g.equalFn != nil {
+		return g
 	}
 
-	return nil
+	return g.withComparison(fn)
 }
 
 func (g *Goldie) equal(actual, expected []byte) bool {
@@ -1,11 +1,14 @@
 package goldie
 
 import (
+	"bytes"
+	"encoding/xml"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCompare(t *testing.T) {
@@ -31,7 +34,7 @@ func TestCompare(t *testing.T) {
 			expectedData: []byte("abc"),
 			update:       false,
 			equalFn:      nil,
-			err:          &errFixtureNotFound{},
+			err:          &FixtureNotFoundError{},
 		},
 		{
 			name:         "example",
@@ -39,7 +42,7 @@ func TestCompare(t *testing.T) {
 			expectedData: []byte("abc"),
 			update:       true,
 			equalFn:      nil,
-			err:          &errFixtureMismatch{},
+			err:          &FixtureMismatchError{},
 		},
 		{
 			name:         "custom equalFn",
@@ -79,6 +82,84 @@ func TestCompare(t *testing.T) {
 	}
 }
 
+func TestCheck(t *testing.T) {
+	tests := map[string]struct {
+		actualData   []byte
+		expectedData []byte
+		create       bool
+		update       bool
+		options      []Option
+		status       Status
+		diff         bool
+	}{
+		"match": {
+			actualData:   []byte("abc"),
+			expectedData: []byte("abc"),
+			create:       true,
+			status:       StatusMatch,
+		},
+		"mismatch": {
+			actualData:   []byte("bc"),
+			expectedData: []byte("abc"),
+			create:       true,
+			status:       StatusMismatch,
+			diff:         true,
+		},
+		"missing": {
+			actualData: []byte("abc"),
+			status:     StatusMissing,
+		},
+		"updated": {
+			actualData:   []byte("bc"),
+			expectedData: []byte("abc"),
+			create:       true,
+			update:       true,
+			status:       StatusUpdated,
+		},
+		"updated mismatch": {
+			actualData:   []byte("bc"),
+			expectedData: []byte("abc"),
+			create:       true,
+			update:       true,
+			options: []Option{WithEqualFn(func(actual, expected []byte) bool {
+				return false
+			})},
+			status: StatusMismatch,
+		},
+	}
+
+	savedUpdateState := *update
+	t.Cleanup(func() {
+		*update = savedUpdateState
+	})
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := New(t, append(test.options, WithFixtureDir(t.TempDir()))...)
+			if test.create {
+				err := g.Update(t, "example", test.expectedData)
+				assert.Nil(t, err)
+			}
+
+			*update = test.update
+			result, err := g.Check(t, "example", test.actualData)
+			*update = savedUpdateState
+			assert.Nil(t, err)
+
+			assert.Equal(t, test.status, result.Status)
+			assert.Equal(t, g.GoldenFileName(t, "example"), result.GoldenFile)
+			assert.Equal(t, test.actualData, result.Actual)
+			assert.Equal(t, test.diff, result.Diff != "")
+			if test.create && !test.update {
+				assert.Equal(t, test.expectedData, result.Expected)
+			}
+
+			err = os.RemoveAll(g.fixtureDir)
+			assert.Nil(t, err)
+		})
+	}
+}
+
 func TestCompareTemplate(t *testing.T) {
 	data := struct {
 		Name string
@@ -108,7 +189,7 @@ func TestCompareTemplate(t *testing.T) {
 			expectedData: []byte("abc {{ .Name }}"),
 			data:         nil,
 			update:       false,
-			err:          &errFixtureNotFound{},
+			err:          &FixtureNotFoundError{},
 		},
 		{
 			name:         "example",
@@ -116,7 +197,7 @@ func TestCompareTemplate(t *testing.T) {
 			expectedData: []byte("abc {{ .Name }}"),
 			data:         data,
 			update:       true,
-			err:          &errFixtureMismatch{},
+			err:          &FixtureMismatchError{},
 		},
 		{
 			name:         "example",
@@ -124,7 +205,7 @@ func TestCompareTemplate(t *testing.T) {
 			expectedData: []byte("abc {{ .Name }}"),
 			data:         nil,
 			update:       true,
-			err:          &errMissingKey{},
+			err:          &MissingKeyError{},
 		},
 		{
 			name:         "example",
@@ -154,6 +235,230 @@ func TestCompareTemplate(t *testing.T) {
 	}
 }
 
+func TestAssertWithTemplateMissingKey(t *testing.T) {
+	g := New(t, WithFixtureDir(t.TempDir()))
+	err := g.Update(t, "example", []byte("abc {{ .Name }}"))
+	assert.Nil(t, err)
+
+	ft := &fakeTB{name: t.Name()}
+	g.AssertWithTemplate(ft, "example", nil, []byte("abc example"))
+	assert.Len(t, ft.errors, 1)
+	assert.False(t, ft.failed)
+}
+
+func TestAssertJsonBytes(t *testing.T) {
+	tests := map[string]struct {
+		golden string
+		failed bool
+	}{
+		"normalized": {
+			golden: "{\n  \"id\": 1,\n  \"tags\": [\n    \"a\"\n  ]\n}",
+		},
+		"different formatting": {
+			golden: `{"tags":["a"],"id":1.0}`,
+		},
+		"mismatch": {
+			golden: `{"tags":["b"],"id":1}`,
+			failed: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := New(t)
+			err := g.Update(t, "example", []byte(test.golden))
+			assert.Nil(t, err)
+
+			ft := &fakeTB{name: t.Name()}
+			g.AssertJsonBytes(ft, "example", []byte(`{ "id": 1, "tags": ["a"] }`))
+			assert.Equal(t, test.failed, len(ft.errors) > 0)
+
+			err = os.RemoveAll(g.fixtureDir)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestAssertWithIgnorePaths(t *testing.T) {
+	type meta struct {
+		RequestID string `json:"requestId" xml:"requestId"`
+		Version   int    `json:"version" xml:"version"`
+	}
+
+	type response struct {
+		XMLName xml.Name `json:"-" xml:"response"`
+		Meta    meta     `json:"meta" xml:"meta"`
+	}
+
+	savedUpdateState := *update
+	t.Cleanup(func() {
+		*update = savedUpdateState
+	})
+
+	g := New(t, WithIgnorePaths("$.meta.requestId", "/response/meta/requestId"))
+
+	*update = true
+	g.AssertJson(t, "json", response{Meta: meta{RequestID: "abc", Version: 1}})
+	g.AssertXml(t, "xml", response{Meta: meta{RequestID: "abc", Version: 1}})
+	*update = savedUpdateState
+
+	data, err := os.ReadFile(g.GoldenFileName(t, "json"))
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n  \"meta\": {\n    \"requestId\": \"<ignored>\",\n    \"version\": 1\n  }\n}", string(data))
+
+	data, err = os.ReadFile(g.GoldenFileName(t, "xml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `<requestId>&lt;ignored&gt;</requestId>`)
+
+	ft := &fakeTB{name: t.Name()}
+	g.AssertJson(ft, "json", response{Meta: meta{RequestID: "def", Version: 1}})
+	g.AssertXml(ft, "xml", response{Meta: meta{RequestID: "def", Version: 1}})
+	assert.Empty(t, ft.errors)
+
+	g.AssertJson(ft, "json", response{Meta: meta{RequestID: "def", Version: 2}})
+	g.AssertXml(ft, "xml", response{Meta: meta{RequestID: "def", Version: 2}})
+	assert.Len(t, ft.errors, 2)
+
+	err = os.RemoveAll(g.fixtureDir)
+	assert.Nil(t, err)
+}
+
+func TestAssertJsonIgnorePathsEncoding(t *testing.T) {
+	type response struct {
+		Version   int    `json:"version"`
+		RequestID string `json:"requestId"`
+	}
+
+	g := New(t, WithFixtureDir(t.TempDir()))
+	err := g.Update(t, "example", []byte("{\n  \"version\": 1,\n  \"requestId\": \"abc\"\n}"))
+	assert.Nil(t, err)
+
+	g = New(t, WithFixtureDir(g.fixtureDir), WithIgnorePaths("$.requestId"))
+
+	ft := &fakeTB{name: t.Name()}
+	g.AssertJson(ft, "example", response{Version: 1, RequestID: "def"})
+	assert.Empty(t, ft.errors)
+
+	g.AssertJson(ft, "example", response{Version: 2, RequestID: "def"})
+	assert.Len(t, ft.errors, 1)
+}
+
+func TestAssertYaml(t *testing.T) {
+	tests := map[string]struct {
+		golden string
+		failed bool
+	}{
+		"canonical": {
+			golden: "items:\n  - 1\n  - 2\nname: example\n",
+		},
+		"different style": {
+			golden: "name: 'example'\nitems: [1, 2]\n",
+		},
+		"mismatch": {
+			golden: "name: example\nitems: [1]\n",
+			failed: true,
+		},
+	}
+
+	data := struct {
+		Name  string `json:"name"`
+		Items []int  `json:"items"`
+	}{
+		Name:  "example",
+		Items: []int{1, 2},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := New(t, WithFixtureDir(t.TempDir()))
+			err := g.Update(t, "example", []byte(test.golden))
+			assert.Nil(t, err)
+
+			ft := &fakeTB{name: t.Name()}
+			g.AssertYaml(ft, "example", data)
+			assert.Equal(t, test.failed, len(ft.errors) > 0)
+
+			ft = &fakeTB{name: t.Name()}
+			g.AssertYamlBytes(ft, "example", []byte("items:\n- 1\n- 2\nname: \"example\"\n"))
+			assert.Equal(t, test.failed, len(ft.errors) > 0)
+
+			err = os.RemoveAll(g.fixtureDir)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestAssertYamlEqualFn(t *testing.T) {
+	equal := func(actual, expected []byte) bool {
+		return bytes.Equal(bytes.ToLower(actual), bytes.ToLower(expected))
+	}
+
+	g := New(t, WithFixtureDir(t.TempDir()), WithEqualFn(equal))
+	err := g.Update(t, "example", []byte("NAME: EXAMPLE\n"))
+	assert.Nil(t, err)
+
+	ft := &fakeTB{name: t.Name()}
+	g.AssertYamlBytes(ft, "example", []byte("name: example\n"))
+	assert.Empty(t, ft.errors)
+
+	g.AssertYamlBytes(ft, "example", []byte("name: 'example'\nother: 1\n"))
+	assert.Len(t, ft.errors, 1)
+}
+
+func TestAssertJsonXmlEqualFn(t *testing.T) {
+	type response struct {
+		RequestId string `json:"requestId" xml:"requestId"`
+		Name      string `json:"name" xml:"name"`
+	}
+
+	var compared [][]byte
+	equal := func(actual, expected []byte) bool {
+		compared = append(compared, actual)
+		return bytes.Equal(bytes.ToLower(actual), bytes.ToLower(expected))
+	}
+
+	g := New(t, WithFixtureDir(t.TempDir()), WithEqualFn(equal),
+		WithIgnorePaths("$.requestId", "/response/requestId"))
+	ft := &fakeTB{name: t.Name()}
+
+	require.NoError(t, g.Update(t, "json", []byte("{\n  \"requestId\": \"1\",\n  \"name\": \"EXAMPLE\"\n}")))
+	g.AssertJson(ft, "json", response{RequestId: "2", Name: "example"})
+	assert.Empty(t, ft.errors)
+	require.Len(t, compared, 1)
+	assert.Contains(t, string(compared[0]), ignoredPlaceholder)
+
+	require.NoError(t, g.Update(t, "json-bytes", []byte(`{"name": "EXAMPLE"}`)))
+	g.AssertJsonBytes(ft, "json-bytes", []byte(`{"name": "example"}`))
+	assert.Empty(t, ft.errors)
+	assert.Len(t, compared, 2)
+
+	require.NoError(t, g.Update(t, "xml", []byte("<response>\n  <requestId>1</requestId>\n  <name>EXAMPLE</name>\n</response>")))
+	g.AssertXml(ft, "xml", response{RequestId: "2", Name: "example"})
+	assert.Empty(t, ft.errors)
+	assert.Len(t, compared, 3)
+
+	g.AssertJsonBytes(ft, "json-bytes", []byte(`{"name": "other"}`))
+	assert.Len(t, ft.errors, 1)
+}
+
+func TestAssertYamlUpdate(t *testing.T) {
+	savedUpdateState := *update
+	*update = true
+	t.Cleanup(func() {
+		*update = savedUpdateState
+	})
+
+	g := New(t, WithFixtureDir(t.TempDir()))
+	g.AssertYamlBytes(t, "example", []byte("name: 'example'\nitems: [1, 2]\n"))
+
+	data, err := os.ReadFile(g.GoldenFileName(t, "example"))
+	assert.Nil(t, err)
+	assert.Equal(t, "items:\n  - 1\n  - 2\nname: example\n", string(data))
+
+	err = os.RemoveAll(g.fixtureDir)
+	assert.Nil(t, err)
+}
+
 func TestNormalizeLF(t *testing.T) {
 	tests := map[string]struct {
 		input     []byte
@@ -0,0 +1,141 @@
+package goldie
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"unicode"
+)
+
+// maxAutoNameLen is the maximum length in bytes of automatically derived
+// fixture names, leaving room for suffixes within the common file name limit
+// of 255 bytes.
+const maxAutoNameLen = 100
+
+// autoNames counts the automatically named assertions of each test.
+var autoNames = newAutoNamer()
+
+// autoNamer numbers the automatically named assertions, so that multiple
+// assertions within one test get distinct fixture names.
+type autoNamer struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newAutoNamer() *autoNamer {
+	return &autoNamer{counts: map[string]int{}}
+}
+
+// next returns the number of the assertion of the golden file in the test,
+// starting at 1. The count is reset when the test finishes, so running the
+// test again, e.g. with `-count`, uses the same fixture names.
+func (a *autoNamer) next(t TB, key string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.counts[key]++
+	n := a.counts[key]
+
+	if c, ok := t.(interface{ Cleanup(func()) }); ok && n == 1 {
+		c.Cleanup(func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			delete(a.counts, key)
+		})
+	}
+
+	return n
+}
+
+// AssertAuto compares the actual data received with the expected data in the
+// golden file, just like Assert, but derives the fixture name from the name
+// of the test. See CheckAuto for how the name is derived.
+func (g *Goldie) AssertAuto(t TB, actualData []byte) {
+	t.Helper()
+	g.Assert(t, g.autoName(t), actualData)
+}
+
+// CheckAuto compares the actual data received with the expected data in the
+// golden file, just like Check, but derives the fixture name from the name of
+// the test.
+//
+// The name is the test name with every character that is not a letter, a
+// digit, `.`, `-` or `_` replaced with `_`. Parts of the test name already
+// used for the directory by WithTestNameForDir or WithSubTestNameForDir are
+// left out. The second and further assertions within the same test get a
+// counter appended, e.g. `TestRender_dark-2`.
+func (g *Goldie) CheckAuto(t TB, actualData []byte) (*Result, error) {
+	return g.Check(t, g.autoName(t), actualData)
+}
+
+// autoName derives the fixture name of the next automatically named
+// assertion in the test.
+func (g *Goldie) autoName(t TB) string {
+	parts := strings.Split(t.Name(), "/")
+	last := parts[len(parts)-1]
+
+	if _, ok := t.(*testing.F); ok || g.useTestNameForDir {
+		parts = parts[1:]
+	}
+	if g.useSubTestNameForDir {
+		parts = nil
+	}
+	if len(parts) == 0 {
+		parts = []string{last}
+	}
+
+	name := sanitizeName(strings.Join(parts, "_"))
+	if n := autoNames.next(t, g.fixtureKey(t, name)); n > 1 {
+		name = fmt.Sprintf("%s-%d", name, n)
+	}
+
+	return name
+}
+
+// sanitizeName turns s into a name that is safe to use as a file name on
+// common file systems.
+func sanitizeName(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	// Windows does not allow file names ending with a dot, and names
+	// starting with a dot are hidden.
+	name := strings.Trim(b.String(), ".")
+	if name == "" {
+		name = "_"
+	}
+
+	if isReservedName(name) {
+		name += "_"
+	}
+
+	if len(name) > maxAutoNameLen {
+		sum := sha256.Sum256([]byte(name))
+		name = strings.ToValidUTF8(name[:maxAutoNameLen-9], "") + "-" + hex.EncodeToString(sum[:4])
+	}
+
+	return name
+}
+
+// isReservedName reports whether the name is reserved for devices on Windows,
+// with or without an extension.
+func isReservedName(name string) bool {
+	base := strings.ToUpper(strings.SplitN(name, ".", 2)[0])
+	switch base {
+	case "CON", "PRN", "AUX", "NUL":
+		return true
+	}
+
+	return len(base) == 4 && (strings.HasPrefix(base, "COM") || strings.HasPrefix(base, "LPT")) &&
+		base[3] >= '1' && base[3] <= '9'
+}
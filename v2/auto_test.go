@@ -0,0 +1,122 @@
+package goldie
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeName(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		expected string
+	}{
+		"plain":           {name: "TestExample", expected: "TestExample"},
+		"subtests":        {name: "TestRender/dark_mode", expected: "TestRender_dark_mode"},
+		"illegal":         {name: `a<b>c:d"e\f|g?h*i`, expected: "a_b_c_d_e_f_g_h_i"},
+		"unicode":         {name: "TestGrüße/日本", expected: "TestGrüße_日本"},
+		"dots":            {name: "..hidden.", expected: "hidden"},
+		"empty":           {name: "", expected: "_"},
+		"reserved":        {name: "con", expected: "con_"},
+		"reserved ext":    {name: "LPT1.txt", expected: "LPT1.txt_"},
+		"not reserved":    {name: "CONFIG", expected: "CONFIG"},
+		"control chars":   {name: "a\x00b\tc", expected: "a_b_c"},
+		"spaces and tabs": {name: "a b", expected: "a_b"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, sanitizeName(test.name))
+		})
+	}
+
+	long := sanitizeName(strings.Repeat("ä", 100))
+	assert.True(t, len(long) <= maxAutoNameLen)
+	assert.NotEqual(t, long, sanitizeName(strings.Repeat("ä", 101)))
+}
+
+func TestAutoName(t *testing.T) {
+	tests := map[string]struct {
+		options  []Option
+		expected []string
+	}{
+		"using defaults": {
+			expected: []string{"testdata/TestRender_dark.golden", "testdata/TestRender_dark-2.golden"},
+		},
+		"with test name for dir": {
+			options:  []Option{WithTestNameForDir(true)},
+			expected: []string{"testdata/TestRender/dark.golden", "testdata/TestRender/dark-2.golden"},
+		},
+		"with sub test name for dir": {
+			options:  []Option{WithTestNameForDir(true), WithSubTestNameForDir(true)},
+			expected: []string{"testdata/TestRender/dark/dark.golden", "testdata/TestRender/dark/dark-2.golden"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			autoNames = newAutoNamer()
+			ft := &fakeTB{name: "TestRender/dark"}
+			g := New(ft, test.options...)
+
+			for _, expected := range test.expected {
+				assert.Equal(t, expected, g.GoldenFileName(ft, g.autoName(ft)))
+			}
+		})
+	}
+}
+
+func TestAssertAuto(t *testing.T) {
+	g := New(t, WithFixtureDir(t.TempDir()))
+	require.NoError(t, g.Update(t, "TestAssertAuto", []byte("first")))
+	require.NoError(t, g.Update(t, "TestAssertAuto-2", []byte("second")))
+
+	g.AssertAuto(t, []byte("first"))
+
+	result, err := g.CheckAuto(t, []byte("second"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusMatch, result.Status)
+}
+
+func TestAutoNameSnapshotsParallel(t *testing.T) {
+	if n, _ := strconv.Atoi(flag.Lookup("test.parallel").Value.String()); n < 4 {
+		t.Skip("needs to run 4 parallel subtests at once, use -parallel 4")
+	}
+
+	g := New(t, WithFixtureDir(t.TempDir()), WithSnapshots(SnapshotPerTest))
+
+	var mu sync.Mutex
+	names := map[string][]string{}
+	record := func(t *testing.T) {
+		name := g.autoName(t)
+		mu.Lock()
+		defer mu.Unlock()
+		names[t.Name()] = append(names[t.Name()], name)
+	}
+
+	// All subtests share the snapshot file, and run their assertions
+	// concurrently.
+	var started sync.WaitGroup
+	started.Add(4)
+	t.Run("group", func(t *testing.T) {
+		for _, sub := range []string{"a", "b", "c", "d"} {
+			t.Run(sub, func(t *testing.T) {
+				t.Parallel()
+				record(t)
+				started.Done()
+				started.Wait()
+				record(t)
+			})
+		}
+	})
+
+	for _, sub := range []string{"a", "b", "c", "d"} {
+		name := sanitizeName(t.Name() + "_group_" + sub)
+		assert.Equal(t, []string{name, name + "-2"}, names[t.Name()+"/group/"+sub])
+	}
+}
@@ -0,0 +1,68 @@
+package goldie
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// cleaned records the fixture directories cleaned during the test run.
+var cleaned = newCleaner()
+
+// cleaner cleans each fixture directory once per test run, before the first
+// golden file is written to it. As cleaning and the directories written to are
+// coordinated through it, parallel tests sharing a fixture directory never
+// remove each other's golden files.
+type cleaner struct {
+	mu   sync.Mutex
+	dirs map[string]bool
+}
+
+func newCleaner() *cleaner {
+	return &cleaner{dirs: map[string]bool{}}
+}
+
+// clean removes the content of the directory in the file system, unless it has been cleaned
+// already. Sub-directories that have been cleaned themselves, and thus may
+// contain golden files written during the test run, are kept.
+func (c *cleaner) clean(fsys WritableFS, dir string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dir = filepath.Clean(dir)
+	if c.dirs[dir] {
+		return nil
+	}
+
+	entries, err := fs.ReadDir(fsys, filepath.ToSlash(dir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if c.inUse(path) {
+			continue
+		}
+
+		if err := fsys.RemoveAll(filepath.ToSlash(path)); err != nil {
+			return err
+		}
+	}
+
+	c.dirs[dir] = true
+	return nil
+}
+
+// inUse reports whether the path is, or contains, a cleaned directory.
+func (c *cleaner) inUse(path string) bool {
+	for dir := range c.dirs {
+		if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,57 @@
+package goldie
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanerKeepsCleanedSubDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"old.golden", "a/old.golden", "b/old.golden"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+
+	c := newCleaner()
+	require.NoError(t, c.clean(osFS{}, filepath.Join(dir, "a")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a", "new.golden"), nil, 0644))
+
+	require.NoError(t, c.clean(osFS{}, dir))
+	require.NoError(t, c.clean(osFS{}, filepath.Join(dir, "a")))
+
+	assertNoFile(t, filepath.Join(dir, "old.golden"))
+	assertNoFile(t, filepath.Join(dir, "a", "old.golden"))
+	assertNoFile(t, filepath.Join(dir, "b"))
+	assertFileContent(t, filepath.Join(dir, "a", "new.golden"), "")
+}
+
+func TestParallelUpdateWithClean(t *testing.T) {
+	savedUpdate, savedClean, savedCleaned := *update, *clean, cleaned
+	*update, *clean, cleaned = true, true, newCleaner()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stale.golden"), nil, 0644))
+
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			name := fmt.Sprintf("example-%d", i)
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				New(t, WithFixtureDir(dir)).Assert(t, name, []byte(name))
+			})
+		}
+	})
+
+	*update, *clean, cleaned = savedUpdate, savedClean, savedCleaned
+
+	assertNoFile(t, filepath.Join(dir, "stale.golden"))
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("example-%d", i)
+		assertFileContent(t, filepath.Join(dir, name+".golden"), name)
+	}
+}
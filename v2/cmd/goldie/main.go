@@ -0,0 +1,378 @@
+// Command goldie manages the golden file fixtures of goldie tests.
+//
+// Usage:
+//
+//	goldie <command> [flags] [dir ...]
+//
+// The commands are:
+//
+//	list    list all golden files
+//	diff    show the difference between pending received files and golden files
+//	accept  promote received files to golden files
+//	prune   list or remove golden files that no test references
+//	stats   show fixture statistics per fixture directory
+//
+// Fixtures are searched for in all fixture directories below the given
+// directories, which default to the current directory. The fixture
+// directory name and the golden file name suffix can be set with the
+// `-fixture-dir` and `-suffix` flags, matching the WithFixtureDir and
+// WithNameSuffix options.
+//
+// The prune command uses a heuristic to find references: a golden file is
+// considered referenced if its name, with or without variant extensions like
+// `.linux`, appears in a Go file of the package, starts with the constant
+// prefix of a string literal, like the `case-` of `fmt.Sprintf("case-%d", i)`,
+// or starts with the name of a test function, like the names derived by
+// AssertAuto. As the heuristic can't see every name, the golden files are
+// only listed, unless `-delete` is given. Only files ending in the non-empty
+// `-suffix` are considered; received files, snapshot files and the fuzz
+// corpus in `testdata/fuzz` are never touched.
+//
+// Pruning based on the golden files actually used during a test run is done
+// by goldie.Main, with the `-orphans` flag of the tests.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sebdah/goldie/v2"
+)
+
+const (
+	// defaultFixtureDir matches the default fixture directory of goldie.
+	defaultFixtureDir = "testdata"
+
+	// defaultFileNameSuffix matches the default golden file name suffix of
+	// goldie.
+	defaultFileNameSuffix = ".golden"
+
+	// receivedSuffix is the suffix of received files.
+	receivedSuffix = ".received"
+
+	// snapshotSuffix is the suffix of snapshot files.
+	snapshotSuffix = ".snap"
+
+	// fuzzDir is the directory of the fuzz corpus within the fixture
+	// directory.
+	fuzzDir = "fuzz"
+)
+
+// command is a sub command of the goldie tool.
+type command struct {
+	name  string
+	usage string
+	run   func(c *config, dirs []string) error
+}
+
+// config holds the flags shared by all commands.
+type config struct {
+	fixtureDir string
+	suffix     string
+	delete     bool
+	stdout     io.Writer
+}
+
+var commands = []command{
+	{name: "list", usage: "list all golden files", run: runList},
+	{name: "diff", usage: "show the difference between pending received files and golden files", run: runDiff},
+	{name: "accept", usage: "promote received files to golden files", run: runAccept},
+	{name: "prune", usage: "list or remove golden files that no test references", run: runPrune},
+	{name: "stats", usage: "show fixture statistics per fixture directory", run: runStats},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command given by args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "goldie: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	c := &config{stdout: stdout}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.fixtureDir, "fixture-dir", defaultFixtureDir, "name of the fixture directories")
+	fs.StringVar(&c.suffix, "suffix", defaultFileNameSuffix, "golden file name suffix")
+	if cmd.name == "prune" {
+		fs.BoolVar(&c.delete, "delete", false, "remove the golden files instead of only listing them")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	dirs := fs.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	if err := cmd.run(c, dirs); err != nil {
+		fmt.Fprintf(stderr, "goldie: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: goldie <command> [flags] [dir ...]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s%s\n", cmd.name, cmd.usage)
+	}
+}
+
+// fixtureDirs returns all fixture directories below the given directories.
+// Hidden directories and vendor directories are skipped.
+func (c *config) fixtureDirs(dirs []string) ([]string, error) {
+	var found []string
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !info.IsDir() {
+				return nil
+			}
+
+			name := info.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "vendor") {
+				return filepath.SkipDir
+			}
+
+			if name == c.fixtureDir {
+				found = append(found, path)
+				return filepath.SkipDir
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(found)
+	return found, nil
+}
+
+// files returns all files with the given suffix in the fixture directory.
+func files(fixtureDir string, suffix string) ([]string, error) {
+	var found []string
+	err := filepath.Walk(fixtureDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && strings.HasSuffix(path, suffix) {
+			found = append(found, path)
+		}
+
+		return nil
+	})
+
+	return found, err
+}
+
+// goldenFiles returns all golden files in the fixture directory. Received
+// files and the fuzz corpus are never considered golden files, even when the
+// suffix is empty.
+func (c *config) goldenFiles(fixtureDir string) ([]string, error) {
+	all, err := files(fixtureDir, c.suffix)
+	if err != nil {
+		return nil, err
+	}
+
+	corpus := filepath.Join(fixtureDir, fuzzDir) + string(filepath.Separator)
+
+	var found []string
+	for _, path := range all {
+		if !strings.HasSuffix(path, receivedSuffix) && !strings.HasPrefix(path, corpus) {
+			found = append(found, path)
+		}
+	}
+
+	return found, nil
+}
+
+// goldenFile returns the golden file belonging to the received file.
+func (c *config) goldenFile(receivedFile string) string {
+	return strings.TrimSuffix(receivedFile, receivedSuffix) + c.suffix
+}
+
+func runList(c *config, dirs []string) error {
+	fixtureDirs, err := c.fixtureDirs(dirs)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range fixtureDirs {
+		golden, err := c.goldenFiles(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range golden {
+			fmt.Fprintln(c.stdout, path)
+		}
+	}
+
+	return nil
+}
+
+func runDiff(c *config, dirs []string) error {
+	fixtureDirs, err := c.fixtureDirs(dirs)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range fixtureDirs {
+		received, err := files(dir, receivedSuffix)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range received {
+			actual, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			goldenFile := c.goldenFile(path)
+			expected, err := os.ReadFile(goldenFile)
+			if os.IsNotExist(err) {
+				fmt.Fprintf(c.stdout, "=== %s (new)\n", goldenFile)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(c.stdout, "=== %s\n", goldenFile)
+			fmt.Fprintln(c.stdout, goldie.Diff(goldie.ClassicDiff, string(actual), string(expected)))
+		}
+	}
+
+	return nil
+}
+
+func runAccept(c *config, dirs []string) error {
+	fixtureDirs, err := c.fixtureDirs(dirs)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range fixtureDirs {
+		accepted, err := goldie.AcceptAll(dir, c.suffix)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range accepted {
+			fmt.Fprintf(c.stdout, "accepted %s\n", path)
+		}
+	}
+
+	return nil
+}
+
+func runPrune(c *config, dirs []string) error {
+	if c.suffix == "" {
+		return fmt.Errorf("prune requires a non-empty -suffix")
+	}
+
+	fixtureDirs, err := c.fixtureDirs(dirs)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range fixtureDirs {
+		sources, err := loadPackageSources(filepath.Dir(dir))
+		if err != nil {
+			return err
+		}
+
+		golden, err := c.goldenFiles(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range golden {
+			if strings.HasSuffix(path, snapshotSuffix) {
+				continue
+			}
+
+			name := strings.TrimSuffix(filepath.Base(path), c.suffix)
+			if sources.references(name) {
+				continue
+			}
+
+			if !c.delete {
+				fmt.Fprintf(c.stdout, "would remove %s\n", path)
+				continue
+			}
+
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.stdout, "removed %s\n", path)
+		}
+	}
+
+	return nil
+}
+
+func runStats(c *config, dirs []string) error {
+	fixtureDirs, err := c.fixtureDirs(dirs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "%-40s %8s %8s %12s\n", "DIRECTORY", "GOLDEN", "RECEIVED", "BYTES")
+	for _, dir := range fixtureDirs {
+		golden, err := c.goldenFiles(dir)
+		if err != nil {
+			return err
+		}
+
+		received, err := files(dir, receivedSuffix)
+		if err != nil {
+			return err
+		}
+
+		var size int64
+		for _, path := range golden {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+
+		fmt.Fprintf(c.stdout, "%-40s %8d %8d %12d\n", dir, len(golden), len(received), size)
+	}
+
+	return nil
+}
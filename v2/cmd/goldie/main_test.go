@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setup creates a package with a fixture directory in a temporary directory.
+func setup(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"pkg/pkg_test.go": `package pkg
+
+func TestExample(t *testing.T) {
+	g.Assert(t, "example", data)
+	g.Assert(t, fmt.Sprintf("case-%d", i), data)
+}
+`,
+		"pkg/testdata/example.golden":    "abc\n",
+		"pkg/testdata/example.received":  "abcd\n",
+		"pkg/testdata/case-1.golden":     "1\n",
+		"pkg/testdata/orphan.golden":     "orphan\n",
+		"pkg/testdata/new.received":      "new\n",
+		"vendor/dep/testdata/dep.golden": "dep\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	return dir
+}
+
+func runCommand(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return stdout.String() + stderr.String(), code
+}
+
+func TestList(t *testing.T) {
+	dir := setup(t)
+	testdata := filepath.Join(dir, "pkg", "testdata")
+
+	out, code := runCommand(t, "list", dir)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, filepath.Join(testdata, "case-1.golden")+"\n"+
+		filepath.Join(testdata, "example.golden")+"\n"+
+		filepath.Join(testdata, "orphan.golden")+"\n", out)
+}
+
+func TestDiff(t *testing.T) {
+	dir := setup(t)
+	testdata := filepath.Join(dir, "pkg", "testdata")
+
+	out, code := runCommand(t, "diff", dir)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "=== "+filepath.Join(testdata, "example.golden")+"\n")
+	assert.Contains(t, out, "-abc\n+abcd\n")
+	assert.Contains(t, out, "=== "+filepath.Join(testdata, "new.golden")+" (new)\n")
+}
+
+func TestAccept(t *testing.T) {
+	dir := setup(t)
+	testdata := filepath.Join(dir, "pkg", "testdata")
+
+	out, code := runCommand(t, "accept", dir)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "accepted "+filepath.Join(testdata, "example.golden"))
+
+	data, err := os.ReadFile(filepath.Join(testdata, "new.golden"))
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", string(data))
+}
+
+func TestPrune(t *testing.T) {
+	dir := setup(t)
+	testdata := filepath.Join(dir, "pkg", "testdata")
+
+	files := map[string]string{
+		"pkg/pkg.go":                          "package pkg\n\nconst fixture = \"from-const\"\n",
+		"pkg/testdata/from-const.golden":      "const\n",
+		"pkg/testdata/example.linux.golden":   "linux\n",
+		"pkg/testdata/TestExample_sub.golden": "auto\n",
+		"pkg/testdata/fuzz/FuzzExample/seed":  "go test fuzz v1\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	out, code := runCommand(t, "prune", dir)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "would remove "+filepath.Join(testdata, "orphan.golden")+"\n", out)
+
+	_, err := os.Stat(filepath.Join(testdata, "orphan.golden"))
+	assert.NoError(t, err)
+
+	out, code = runCommand(t, "prune", "-delete", dir)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "removed "+filepath.Join(testdata, "orphan.golden")+"\n", out)
+
+	_, err = os.Stat(filepath.Join(testdata, "orphan.golden"))
+	assert.True(t, os.IsNotExist(err))
+	for _, name := range []string{"case-1.golden", "from-const.golden", "example.linux.golden", "TestExample_sub.golden", "fuzz/FuzzExample/seed"} {
+		_, err = os.Stat(filepath.Join(testdata, name))
+		assert.NoError(t, err, name)
+	}
+
+	_, code = runCommand(t, "prune", "-delete", "-suffix", "", dir)
+	assert.Equal(t, 1, code)
+	_, err = os.Stat(filepath.Join(testdata, "fuzz", "FuzzExample", "seed"))
+	assert.NoError(t, err)
+}
+
+func TestStats(t *testing.T) {
+	dir := setup(t)
+
+	out, code := runCommand(t, "stats", dir)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "DIRECTORY")
+	assert.Regexp(t, `testdata\s+3\s+2\s+13\n`, out)
+}
+
+func TestUnknownCommand(t *testing.T) {
+	out, code := runCommand(t, "unknown")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, out, "unknown command")
+
+	_, code = runCommand(t)
+	assert.Equal(t, 2, code)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// minPrefixLen is the minimum length of a string literal prefix, e.g. the
+// part of a format string before the first verb, to count as a reference to
+// a golden file.
+const minPrefixLen = 3
+
+// testFuncPrefixes are the prefixes of the names of test functions, from
+// which AssertAuto derives fixture names.
+var testFuncPrefixes = []string{"Test", "Fuzz", "Benchmark", "Example"}
+
+// packageSources holds the Go files of a package, including non-test files
+// which may define fixture names in constants or helpers.
+type packageSources struct {
+	sources   []string
+	literals  []string
+	testFuncs []string
+}
+
+// loadPackageSources reads all Go files in the package directory.
+func loadPackageSources(dir string) (*packageSources, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	ps := &packageSources{}
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		ps.sources = append(ps.sources, string(src))
+
+		var s scanner.Scanner
+		fset := token.NewFileSet()
+		s.Init(fset.AddFile(path, -1, len(src)), src, nil, 0)
+
+		prev := token.ILLEGAL
+		for {
+			_, tok, lit := s.Scan()
+			if tok == token.EOF {
+				break
+			}
+
+			switch {
+			case tok == token.STRING:
+				if v, err := strconv.Unquote(lit); err == nil {
+					ps.literals = append(ps.literals, v)
+				}
+			case tok == token.IDENT && prev == token.FUNC && isTestFunc(lit):
+				ps.testFuncs = append(ps.testFuncs, lit)
+			}
+			prev = tok
+		}
+	}
+
+	return ps, nil
+}
+
+// isTestFunc reports whether the function name is the name of a test,
+// fuzz target, benchmark or example.
+func isTestFunc(name string) bool {
+	for _, prefix := range testFuncPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// references reports whether the golden file with the given name is
+// referenced by any of the Go files. This is a heuristic: the name, or the
+// name without variant extensions like the `.linux` of `example.linux`, has
+// to appear in the source, start with the constant prefix of a string
+// literal, like the `case-` of `fmt.Sprintf("case-%d", i)`, or start with the
+// name of a test function, as names derived by AssertAuto do.
+func (ps *packageSources) references(name string) bool {
+	for {
+		if ps.referencesName(name) {
+			return true
+		}
+
+		i := strings.LastIndexByte(name, '.')
+		if i <= 0 {
+			return false
+		}
+		name = name[:i]
+	}
+}
+
+func (ps *packageSources) referencesName(name string) bool {
+	for _, src := range ps.sources {
+		if strings.Contains(src, name) {
+			return true
+		}
+	}
+
+	for _, lit := range ps.literals {
+		if i := strings.IndexByte(lit, '%'); i >= 0 {
+			lit = lit[:i]
+		}
+
+		if len(lit) >= minPrefixLen && strings.HasPrefix(name, lit) {
+			return true
+		}
+	}
+
+	for _, fn := range ps.testFuncs {
+		if name == fn || strings.HasPrefix(name, fn+"_") || strings.HasPrefix(name, fn+"-") {
+			return true
+		}
+	}
+
+	return false
+}
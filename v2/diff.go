@@ -0,0 +1,285 @@
+package goldie
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// ANSI escape sequences used by the colored diff engines. Content only in the
+// expected data is red, content only in the actual data is green.
+const (
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiReset = "\x1b[0m"
+)
+
+// diffOptions holds the settings of the diff engines.
+type diffOptions struct {
+	// width is the width of the diff output if the COLUMNS environment
+	// variable is not set and the width of the terminal can't be detected.
+	width int
+
+	// context is the number of unchanged lines shown around changes.
+	context int
+
+	// color enables ANSI colors in the diff engines supporting both colored
+	// and plain output.
+	color bool
+
+	// invisibles makes invisible characters visible on changed lines.
+	invisibles bool
+
+	// maxLines and maxSize limit the number of lines and bytes of the diff
+	// output. Zero means unlimited.
+	maxLines int
+	maxSize  int
+
+	// timeout is the time budget of the diff algorithm. Zero keeps the
+	// default of diffmatchpatch and diffs inputs of any size.
+	timeout time.Duration
+}
+
+// maxDiffInputLines is the number of lines of the expected or actual data
+// above which the line based engines, which take quadratic time in the worst
+// case, are skipped when a time budget is set.
+const maxDiffInputLines = 10000
+
+// newDiffOptions returns the default settings of the diff engines.
+func newDiffOptions() diffOptions {
+	return diffOptions{
+		width:      defaultDiffWidth,
+		context:    defaultDiffContext,
+		color:      defaultDiffColor,
+		invisibles: defaultDiffInvisibles,
+		maxLines:   defaultDiffMaxLines,
+		maxSize:    defaultDiffMaxSize,
+		timeout:    defaultDiffTimeout,
+	}
+}
+
+// detectTerminalWidth returns the width of the terminal, or 0 if it can't be
+// detected. Tests replace it to not depend on the terminal they run in.
+var detectTerminalWidth = terminalColumns
+
+// terminalWidth returns the width of the diff output: the COLUMNS environment
+// variable if set, or else the width of the terminal, falling back to the
+// configured width if the tests don't run in one, e.g. in CI.
+func (o diffOptions) terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+
+	if columns := detectTerminalWidth(); columns > 0 {
+		return columns
+	}
+
+	return o.width
+}
+
+// splitLines splits the text into lines, without their line breaks. A final
+// line break does not start another, empty line.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffMatchPatch returns a diffmatchpatch instance limited to the time budget,
+// if set.
+func (o diffOptions) diffMatchPatch() *diffmatchpatch.DiffMatchPatch {
+	dmp := diffmatchpatch.New()
+	if o.timeout > 0 {
+		dmp.DiffTimeout = o.timeout
+	}
+
+	return dmp
+}
+
+// engine returns the engine rendering the diff of the data. Binary data is
+// always shown as a hex diff.
+func (o diffOptions) engine(engine DiffEngine, actual, expected string) DiffEngine {
+	if isBinary(actual) || isBinary(expected) {
+		return HexDiff
+	}
+
+	return engine
+}
+
+// tooLarge reports whether the data is too large to be diffed by the engine
+// within the time budget. That's the case for the line based engines and data
+// of more than maxDiffInputLines lines. The other engines run in linear time
+// or are bounded by the time budget of diffmatchpatch.
+func (o diffOptions) tooLarge(engine DiffEngine, actual, expected string) bool {
+	switch o.engine(engine, actual, expected) {
+	case ClassicDiff, JsonDiff, SideBySideDiff, InlineDiff:
+		return strings.Count(actual, "\n") > maxDiffInputLines ||
+			strings.Count(expected, "\n") > maxDiffInputLines
+	}
+
+	return false
+}
+
+// summarizeLines describes the difference between data too large to diff by
+// the number of lines and the first differing line.
+func summarizeLines(actual, expected string) string {
+	a, b := splitLines(expected), splitLines(actual)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Data too large to diff: expected %s, got %s\n", plural(len(a), "line"), plural(len(b), "line"))
+
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if i < len(a) && i < len(b) && a[i] == b[i] {
+			continue
+		}
+
+		fmt.Fprintf(&buf, "First difference at line %d:\n", i+1)
+		if i < len(a) {
+			buf.WriteString("-" + a[i] + "\n")
+		}
+		if i < len(b) {
+			buf.WriteString("+" + b[i] + "\n")
+		}
+		break
+	}
+
+	return buf.String()
+}
+
+// changedRunes compares two versions of a line and reports, for every rune of
+// each version, whether it was changed.
+func (o diffOptions) changedRunes(expected, actual string) (expectedMask, actualMask []bool) {
+	dmp := o.diffMatchPatch()
+	diffs := dmp.DiffCleanupSemantic(dmp.DiffMain(expected, actual, false))
+
+	for _, d := range diffs {
+		n := utf8.RuneCountInString(d.Text)
+		for i := 0; i < n; i++ {
+			switch d.Type {
+			case diffmatchpatch.DiffEqual:
+				expectedMask = append(expectedMask, false)
+				actualMask = append(actualMask, false)
+			case diffmatchpatch.DiffDelete:
+				expectedMask = append(expectedMask, true)
+			case diffmatchpatch.DiffInsert:
+				actualMask = append(actualMask, true)
+			}
+		}
+	}
+
+	return expectedMask, actualMask
+}
+
+// truncate limits the diff to the maximal number of lines and bytes, followed
+// by a summary of what was omitted. Unified diffs are cut between hunks, as
+// long as at least one hunk is kept.
+func (o diffOptions) truncate(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if (o.maxLines <= 0 || len(lines) <= o.maxLines) && (o.maxSize <= 0 || len(diff) <= o.maxSize) {
+		return diff
+	}
+
+	kept, size := 0, 0
+	for kept < len(lines) && (o.maxLines <= 0 || kept < o.maxLines) &&
+		(o.maxSize <= 0 || size+len(lines[kept]) <= o.maxSize) {
+		size += len(lines[kept])
+		kept++
+	}
+
+	// A single line exceeding the maximal size is cut, rather than omitting
+	// everything.
+	partial := ""
+	if kept == 0 {
+		partial = truncateUTF8(lines[0], o.maxSize) + "\n"
+	}
+
+	var hunks []int
+	for i, line := range lines {
+		if strings.HasPrefix(line, "@@ ") {
+			hunks = append(hunks, i)
+		}
+	}
+
+	cut := -1
+	for _, h := range hunks {
+		if h > hunks[0] && h <= kept {
+			cut = h
+		}
+	}
+
+	var summary string
+	if cut >= 0 {
+		kept = cut
+		summary = fmt.Sprintf("... %s omitted", plural(len(hunks)-indexOf(hunks, cut), "more hunk"))
+	} else {
+		summary = fmt.Sprintf("... %s omitted", plural(len(lines)-kept, "more line"))
+
+		remaining := 0
+		for _, h := range hunks {
+			if h >= kept {
+				remaining++
+			}
+		}
+		if remaining > 0 {
+			summary += fmt.Sprintf(", including %s", plural(remaining, "more hunk"))
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString(strings.Join(lines[:kept], ""))
+	buf.WriteString(partial)
+	if !strings.HasSuffix(buf.String(), "\n") && buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		buf.WriteString(ansiReset)
+	}
+	buf.WriteString(summary + "\n")
+
+	return buf.String()
+}
+
+// truncateUTF8 cuts the text to at most size bytes, without splitting runes.
+func truncateUTF8(text string, size int) string {
+	if len(text) <= size {
+		return text
+	}
+
+	for size > 0 && !utf8.RuneStart(text[size]) {
+		size--
+	}
+
+	return text[:size]
+}
+
+// indexOf returns the index of the value in the values, or -1 if missing.
+func indexOf(values []int, value int) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// plural formats the count with the noun, adding an `s` unless the count is
+// one.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,133 @@
+package goldie
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTruncate(t *testing.T) {
+	unified := "--- Expected\n+++ Actual\n" +
+		"@@ -1 +1 @@\n-a\n+b\n" +
+		"@@ -5 +5 @@\n-c\n+d\n" +
+		"@@ -9 +9 @@\n-e\n+f\n"
+
+	tests := map[string]struct {
+		diff     string
+		maxLines int
+		maxSize  int
+		expected string
+	}{
+		"within limits": {
+			diff:     unified,
+			maxLines: 11,
+			maxSize:  len(unified),
+			expected: unified,
+		},
+		"unlimited": {
+			diff:     unified,
+			expected: unified,
+		},
+		"between hunks": {
+			diff:     unified,
+			maxLines: 9,
+			expected: "--- Expected\n+++ Actual\n" +
+				"@@ -1 +1 @@\n-a\n+b\n" +
+				"@@ -5 +5 @@\n-c\n+d\n" +
+				"... 1 more hunk omitted\n",
+		},
+		"within the first hunk": {
+			diff:     unified,
+			maxLines: 4,
+			expected: "--- Expected\n+++ Actual\n" +
+				"@@ -1 +1 @@\n-a\n" +
+				"... 7 more lines omitted, including 2 more hunks\n",
+		},
+		"by size": {
+			diff:    unified,
+			maxSize: 45,
+			expected: "--- Expected\n+++ Actual\n" +
+				"@@ -1 +1 @@\n-a\n+b\n" +
+				"... 2 more hunks omitted\n",
+		},
+		"lines": {
+			diff:     "Expected: a\nb\nc\nGot: d",
+			maxLines: 2,
+			expected: "Expected: a\nb\n... 2 more lines omitted\n",
+		},
+		"long line": {
+			diff:     "Expected: " + strings.Repeat("ä", 10),
+			maxSize:  15,
+			expected: "Expected: ää\n... 1 more line omitted\n",
+		},
+		"colored": {
+			diff:     "\x1b[31ma\nb\x1b[0m\n",
+			maxLines: 1,
+			expected: "\x1b[31ma\n\x1b[0m... 1 more line omitted\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := diffOptions{maxLines: test.maxLines, maxSize: test.maxSize}
+			assert.Equal(t, test.expected, opts.truncate(test.diff))
+		})
+	}
+}
+
+func TestDiffContext(t *testing.T) {
+	opts := newDiffOptions()
+	opts.context = 2
+
+	assert.Equal(t, `--- Expected
++++ Actual
+@@ -1,5 +1,5 @@
+ a
+ b
+-c
++C
+ d
+ e
+`, opts.diff(ClassicDiff, "a\nb\nC\nd\ne\nf\n", "a\nb\nc\nd\ne\nf\n"))
+}
+
+func TestWithDiffLimits(t *testing.T) {
+	g := New(t, WithDiffLimits(10, 0), WithDiffTimeout(time.Minute))
+	assert.Equal(t, 10, g.diffOptions.maxLines)
+	assert.Equal(t, 0, g.diffOptions.maxSize)
+	assert.Equal(t, time.Minute, g.diffOptions.diffMatchPatch().DiffTimeout)
+
+	assert.Error(t, g.WithDiffLimits(-1, 0))
+	assert.Error(t, g.WithDiffTimeout(-time.Second))
+}
+
+func TestDiffTimeout(t *testing.T) {
+	opts := newDiffOptions()
+	assert.Equal(t, diffmatchpatch.New().DiffTimeout, opts.diffMatchPatch().DiffTimeout)
+
+	expected := strings.Repeat("abc\n", maxDiffInputLines) + "x\n"
+	actual := strings.Repeat("abc\n", maxDiffInputLines-1) + "abd\ny\n"
+
+	// Without a time budget, inputs of any size are diffed.
+	assert.Contains(t, opts.diff(ClassicDiff, actual, expected), "@@")
+
+	opts.timeout = time.Minute
+	assert.Equal(t, "Data too large to diff: expected 10001 lines, got 10001 lines\n"+
+		"First difference at line 10000:\n-abc\n+abd\n", opts.diff(ClassicDiff, actual, expected))
+
+	// diffmatchpatch runs out of time on its own.
+	opts.timeout = time.Nanosecond
+	assert.NotEmpty(t, opts.diff(ColoredDiff, actual, expected))
+}
+
+func TestDiffUnlimited(t *testing.T) {
+	expected := strings.Repeat("abc\n", 2000)
+	actual := strings.Repeat("abd\n", 2000)
+
+	diff := Diff(ClassicDiff, actual, expected)
+	assert.NotContains(t, diff, "omitted")
+	assert.True(t, strings.Count(diff, "\n") > 4000)
+}
@@ -1,74 +1,265 @@
 package goldie
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
-// errFixtureNotFound is thrown when the fixture file could not be found.
-type errFixtureNotFound struct {
-	message string
+var (
+	// ErrFixtureNotFound is matched by errors.Is when the golden fixture file
+	// could not be found.
+	ErrFixtureNotFound = errors.New("golden fixture not found")
+
+	// ErrFixtureMismatch is matched by errors.Is when the actual and expected
+	// data is not matching.
+	ErrFixtureMismatch = errors.New("golden fixture mismatch")
+
+	// ErrFixtureDirectoryIsFile is matched by errors.Is when the fixture
+	// directory is a file.
+	ErrFixtureDirectoryIsFile = errors.New("fixture folder is a file")
+
+	// ErrMissingKey is matched by errors.Is when a value for a template is
+	// missing.
+	ErrMissingKey = errors.New("template value is missing")
+
+	// ErrDuplicateGoldenFile is matched by errors.Is when the same golden
+	// file is asserted twice during the test run with different content.
+	ErrDuplicateGoldenFile = errors.New("golden fixture asserted twice")
+
+	// ErrInvalidFixtureName is matched by errors.Is when a fixture name is
+	// rejected by the name policy.
+	ErrInvalidFixtureName = errors.New("invalid golden fixture name")
+
+	// ErrFixturePathEscape is matched by errors.Is when a golden file is
+	// outside of the fixture directory.
+	ErrFixturePathEscape = errors.New("golden fixture outside of fixture folder")
+)
+
+// FixtureNotFoundError is returned when the fixture file could not be found.
+type FixtureNotFoundError struct {
+	// GoldenFile is the path of the missing golden file.
+	GoldenFile string
+
+	// TestName is the name of the test asserting the golden file.
+	TestName string
 }
 
 // newErrFixtureNotFound returns a new instance of the error.
-func newErrFixtureNotFound() *errFixtureNotFound {
-	return &errFixtureNotFound{
-		// TODO: flag name should be based on the variable value
-		message: "Golden fixture not found. Try running with -update flag.",
+func newErrFixtureNotFound(goldenFile, testName string) *FixtureNotFoundError {
+	return &FixtureNotFoundError{
+		GoldenFile: goldenFile,
+		TestName:   testName,
 	}
 }
 
 // Error returns the error message.
-func (e *errFixtureNotFound) Error() string {
-	return e.message
+func (e *FixtureNotFoundError) Error() string {
+	// TODO: flag name should be based on the variable value
+	return "Golden fixture not found. Try running with -update flag."
 }
 
-// errFixtureMismatch is thrown when the actual and expected data is not
+// Is reports whether target is ErrFixtureNotFound.
+func (e *FixtureNotFoundError) Is(target error) bool {
+	return target == ErrFixtureNotFound
+}
+
+// FixtureMismatchError is returned when the actual and expected data is not
 // matching.
-type errFixtureMismatch struct {
-	message string
+type FixtureMismatchError struct {
+	// GoldenFile is the path of the golden file that was compared.
+	GoldenFile string
+
+	// TestName is the name of the test asserting the golden file.
+	TestName string
+
+	// Expected is the data read from the golden file.
+	Expected []byte
+
+	// Actual is the data that was compared with the golden file.
+	Actual []byte
+
+	// Diff is the rendered difference between expected and actual data.
+	Diff string
 }
 
 // newErrFixtureMismatch returns a new instance of the error.
-func newErrFixtureMismatch(message string) *errFixtureMismatch {
-	return &errFixtureMismatch{
-		message: message,
+func newErrFixtureMismatch(result *Result) *FixtureMismatchError {
+	return &FixtureMismatchError{
+		GoldenFile: result.GoldenFile,
+		TestName:   result.TestName,
+		Expected:   result.Expected,
+		Actual:     result.Actual,
+		Diff:       result.Diff,
 	}
 }
 
-func (e *errFixtureMismatch) Error() string {
-	return e.message
+// Error returns the error message.
+func (e *FixtureMismatchError) Error() string {
+	return "Result did not match the golden fixture. Diff is below:\n\n" + e.Diff
 }
 
-// errFixtureDirecetoryIsFile is thrown when the fixture directory is a file
-type errFixtureDirectoryIsFile struct {
-	file string
+// Is reports whether target is ErrFixtureMismatch.
+func (e *FixtureMismatchError) Is(target error) bool {
+	return target == ErrFixtureMismatch
 }
 
-// newFixtureDirectoryIsFile returns a new instance of the error.
-func newErrFixtureDirectoryIsFile(file string) *errFixtureDirectoryIsFile {
-	return &errFixtureDirectoryIsFile{
-		file: file,
+// FixtureDirectoryIsFileError is returned when the fixture directory is a
+// file.
+type FixtureDirectoryIsFileError struct {
+	// Dir is the fixture directory that turned out to be a file.
+	Dir string
+}
+
+// newErrFixtureDirectoryIsFile returns a new instance of the error.
+func newErrFixtureDirectoryIsFile(dir string) *FixtureDirectoryIsFileError {
+	return &FixtureDirectoryIsFileError{
+		Dir: dir,
 	}
 }
 
-func (e *errFixtureDirectoryIsFile) Error() string {
-	return fmt.Sprintf("fixture folder is a file: %s", e.file)
+// Error returns the error message.
+func (e *FixtureDirectoryIsFileError) Error() string {
+	return fmt.Sprintf("fixture folder is a file: %s", e.Dir)
+}
+
+// File returns the fixture directory that turned out to be a file.
+func (e *FixtureDirectoryIsFileError) File() string {
+	return e.Dir
 }
 
-func (e *errFixtureDirectoryIsFile) File() string {
-	return e.file
+// Is reports whether target is ErrFixtureDirectoryIsFile.
+func (e *FixtureDirectoryIsFileError) Is(target error) bool {
+	return target == ErrFixtureDirectoryIsFile
 }
 
-// errMissingKey is thrown when a value for a template is missing
-type errMissingKey struct {
-	message string
+// MissingKeyError is returned when a value for a template is missing.
+type MissingKeyError struct {
+	// GoldenFile is the path of the golden file holding the template.
+	GoldenFile string
+
+	// TestName is the name of the test asserting the golden file.
+	TestName string
+
+	// Err is the underlying template execution error.
+	Err error
 }
 
 // newErrMissingKey returns a new instance of the error.
-func newErrMissingKey(message string) *errMissingKey {
-	return &errMissingKey{
-		message: message,
+func newErrMissingKey(goldenFile, testName string, err error) *MissingKeyError {
+	return &MissingKeyError{
+		GoldenFile: goldenFile,
+		TestName:   testName,
+		Err:        err,
+	}
+}
+
+// Error returns the error message.
+func (e *MissingKeyError) Error() string {
+	return fmt.Sprintf("Template error: %s", e.Err)
+}
+
+// Unwrap returns the underlying template execution error.
+func (e *MissingKeyError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrMissingKey.
+func (e *MissingKeyError) Is(target error) bool {
+	return target == ErrMissingKey
+}
+
+// DuplicateGoldenFileError is returned when the same golden file is asserted
+// more than once during the test run, with different content.
+type DuplicateGoldenFileError struct {
+	// GoldenFile is the path of the golden file asserted more than once.
+	GoldenFile string
+
+	// TestName is the name of the test asserting the golden file again.
+	TestName string
+
+	// PreviousTestName is the name of the test that asserted the golden file
+	// first.
+	PreviousTestName string
+}
+
+// newErrDuplicateGoldenFile returns a new instance of the error.
+func newErrDuplicateGoldenFile(goldenFile, testName, previousTestName string) *DuplicateGoldenFileError {
+	return &DuplicateGoldenFileError{
+		GoldenFile:       goldenFile,
+		TestName:         testName,
+		PreviousTestName: previousTestName,
+	}
+}
+
+// Error returns the error message.
+func (e *DuplicateGoldenFileError) Error() string {
+	return fmt.Sprintf(
+		"golden fixture %s is asserted with different content by %s and %s; use a unique name for each assertion",
+		e.GoldenFile, e.PreviousTestName, e.TestName,
+	)
+}
+
+// Is reports whether target is ErrDuplicateGoldenFile.
+func (e *DuplicateGoldenFileError) Is(target error) bool {
+	return target == ErrDuplicateGoldenFile
+}
+
+// InvalidFixtureNameError is returned when a fixture name, or a test name used
+// for the fixture directory, is rejected by the name policy.
+type InvalidFixtureNameError struct {
+	// Name is the rejected part of the golden file path.
+	Name string
+
+	// TestName is the name of the test asserting the golden file.
+	TestName string
+}
+
+// newErrInvalidFixtureName returns a new instance of the error.
+func newErrInvalidFixtureName(name, testName string) *InvalidFixtureNameError {
+	return &InvalidFixtureNameError{
+		Name:     name,
+		TestName: testName,
 	}
 }
 
-func (e *errMissingKey) Error() string {
-	return e.message
+// Error returns the error message.
+func (e *InvalidFixtureNameError) Error() string {
+	return fmt.Sprintf("invalid golden fixture name %q: only letters, digits, '.', '-' and '_' are allowed", e.Name)
+}
+
+// Is reports whether target is ErrInvalidFixtureName.
+func (e *InvalidFixtureNameError) Is(target error) bool {
+	return target == ErrInvalidFixtureName
+}
+
+// FixturePathEscapeError is returned when a golden file resolves to a path
+// outside of the fixture directory, e.g. because its name contains `../`.
+type FixturePathEscapeError struct {
+	// GoldenFile is the resolved path of the golden file.
+	GoldenFile string
+
+	// FixtureDir is the fixture directory the golden file should be in.
+	FixtureDir string
+
+	// TestName is the name of the test asserting the golden file.
+	TestName string
+}
+
+// newErrFixturePathEscape returns a new instance of the error.
+func newErrFixturePathEscape(goldenFile, fixtureDir, testName string) *FixturePathEscapeError {
+	return &FixturePathEscapeError{
+		GoldenFile: goldenFile,
+		FixtureDir: fixtureDir,
+		TestName:   testName,
+	}
+}
+
+// Error returns the error message.
+func (e *FixturePathEscapeError) Error() string {
+	return fmt.Sprintf("golden fixture %s is outside of the fixture folder %s", e.GoldenFile, e.FixtureDir)
+}
+
+// Is reports whether target is ErrFixturePathEscape.
+func (e *FixturePathEscapeError) Is(target error) bool {
+	return target == ErrFixturePathEscape
 }
@@ -1,25 +1,46 @@
 package goldie
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestErrFixtureNotFound(t *testing.T) {
 	expected := "Golden fixture not found. Try running with -update flag."
-	err := newErrFixtureNotFound()
+	err := newErrFixtureNotFound("testdata/example.golden", "TestExample")
 
 	assert.Equal(t, expected, err.Error())
-	assert.IsType(t, &errFixtureNotFound{}, err)
+	assert.IsType(t, &FixtureNotFoundError{}, err)
+	assert.Equal(t, "testdata/example.golden", err.GoldenFile)
+	assert.Equal(t, "TestExample", err.TestName)
+	assert.True(t, errors.Is(err, ErrFixtureNotFound))
+	assert.False(t, errors.Is(err, ErrFixtureMismatch))
 }
 
 func TestErrFixtureMismatch(t *testing.T) {
-	message := "example message"
-	err := newErrFixtureMismatch(message)
+	message := "Result did not match the golden fixture. Diff is below:\n\nexample diff"
+	err := newErrFixtureMismatch(&Result{
+		GoldenFile: "testdata/example.golden",
+		TestName:   "TestExample",
+		Expected:   []byte("abc"),
+		Actual:     []byte("bc"),
+		Diff:       "example diff",
+	})
 
 	assert.Equal(t, message, err.Error())
-	assert.IsType(t, &errFixtureMismatch{}, err)
+	assert.IsType(t, &FixtureMismatchError{}, err)
+	assert.Equal(t, []byte("abc"), err.Expected)
+	assert.Equal(t, []byte("bc"), err.Actual)
+	assert.True(t, errors.Is(err, ErrFixtureMismatch))
+
+	var e *FixtureMismatchError
+	assert.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &e))
+	assert.Equal(t, "testdata/example.golden", e.GoldenFile)
 }
 
 func TestErrFixtureDirectoryIsFile(t *testing.T) {
@@ -28,5 +49,68 @@ func TestErrFixtureDirectoryIsFile(t *testing.T) {
 	err := newErrFixtureDirectoryIsFile(location)
 
 	assert.Equal(t, message, err.Error())
-	assert.IsType(t, &errFixtureDirectoryIsFile{}, err)
+	assert.IsType(t, &FixtureDirectoryIsFileError{}, err)
+	assert.Equal(t, location, err.File())
+	assert.True(t, errors.Is(err, ErrFixtureDirectoryIsFile))
+}
+
+func TestErrMissingKey(t *testing.T) {
+	cause := errors.New("map has no entry for key")
+	err := newErrMissingKey("testdata/example.golden", "TestExample", cause)
+
+	assert.Equal(t, "Template error: map has no entry for key", err.Error())
+	assert.IsType(t, &MissingKeyError{}, err)
+	assert.True(t, errors.Is(err, ErrMissingKey))
+	assert.True(t, errors.Is(err, cause))
+}
+
+func TestErrDuplicateGoldenFile(t *testing.T) {
+	err := newErrDuplicateGoldenFile("testdata/example.golden", "TestB", "TestA")
+
+	assert.Equal(t, "golden fixture testdata/example.golden is asserted with different content by TestA and TestB; use a unique name for each assertion", err.Error())
+	assert.IsType(t, &DuplicateGoldenFileError{}, err)
+	assert.Equal(t, "TestA", err.PreviousTestName)
+	assert.True(t, errors.Is(err, ErrDuplicateGoldenFile))
+	assert.False(t, errors.Is(err, ErrFixtureMismatch))
+}
+
+func TestErrInvalidFixtureName(t *testing.T) {
+	err := newErrInvalidFixtureName("a:b", "TestExample")
+
+	assert.Equal(t, `invalid golden fixture name "a:b": only letters, digits, '.', '-' and '_' are allowed`, err.Error())
+	assert.IsType(t, &InvalidFixtureNameError{}, err)
+	assert.Equal(t, "TestExample", err.TestName)
+	assert.True(t, errors.Is(err, ErrInvalidFixtureName))
+}
+
+func TestErrFixturePathEscape(t *testing.T) {
+	err := newErrFixturePathEscape("example.golden", "testdata", "TestExample")
+
+	assert.Equal(t, "golden fixture example.golden is outside of the fixture folder testdata", err.Error())
+	assert.IsType(t, &FixturePathEscapeError{}, err)
+	assert.Equal(t, "example.golden", err.GoldenFile)
+	assert.True(t, errors.Is(err, ErrFixturePathEscape))
+}
+
+// permissionFS fails to read any file with fs.ErrPermission.
+type permissionFS struct{}
+
+func (permissionFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+func TestErrWrapped(t *testing.T) {
+	g := New(t, WithFS(permissionFS{}))
+
+	_, err := g.Check(t, "example", []byte("abc"))
+	assert.True(t, errors.Is(err, fs.ErrPermission), err)
+
+	_, err = g.CheckWithTemplate(t, "example", nil, []byte("abc"))
+	assert.True(t, errors.Is(err, fs.ErrPermission), err)
+
+	fsys := fstest.MapFS{"testdata/template.golden": {Data: []byte("{{")}}
+	g = New(t, WithFS(fsys))
+	_, err = g.CheckWithTemplate(t, "template", nil, []byte("abc"))
+	assert.Contains(t, err.Error(), "testdata/template.golden")
+	assert.NotNil(t, errors.Unwrap(err))
 }
@@ -0,0 +1,133 @@
+package goldie
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// WritableFS is a file system golden files can be written to, e.g. when
+// updating them. Names are slash-separated paths, just like for fs.FS.
+type WritableFS interface {
+	fs.FS
+
+	// WriteFile writes the data to the named file, replacing it if it
+	// exists.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+
+	// MkdirAll creates the named directory, along with any missing parents.
+	MkdirAll(name string, perm fs.FileMode) error
+
+	// Rename renames the file oldname to newname, replacing newname if it
+	// exists.
+	Rename(oldname, newname string) error
+
+	// RemoveAll removes the named file or directory and any children it
+	// contains. It's not an error if the name does not exist.
+	RemoveAll(name string) error
+}
+
+// fsysID identifies the file system within the test run. It's empty for the
+// default file system, and otherwise names the type and the instance: its
+// address for file systems with reference semantics like fstest.MapFS, or
+// its value, like the root directory of os.DirFS.
+func fsysID(fsys fs.FS) string {
+	if _, ok := fsys.(osFS); ok {
+		return ""
+	}
+
+	v := reflect.ValueOf(fsys)
+	switch v.Kind() {
+	case reflect.Map, reflect.Ptr, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return fmt.Sprintf("%T@%x:", fsys, v.Pointer())
+	}
+
+	return fmt.Sprintf("%T(%v):", fsys, fsys)
+}
+
+// osFS is the default file system, backed by the os package. Unlike
+// os.DirFS, it accepts any path, relative to the working directory or
+// absolute.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(filepath.FromSlash(name))
+}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(filepath.FromSlash(name))
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.FromSlash(name))
+}
+
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(filepath.FromSlash(name))
+}
+
+// WriteFile atomically replaces the file with the data, by writing it to a
+// temporary file in the same directory first and renaming it afterwards.
+// Readers, including parallel tests, thus never see a partially written file.
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	name = filepath.FromSlash(name)
+
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, name)
+}
+
+func (osFS) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(filepath.FromSlash(name), perm)
+}
+
+func (osFS) Rename(oldname, newname string) error {
+	return os.Rename(filepath.FromSlash(oldname), filepath.FromSlash(newname))
+}
+
+func (osFS) RemoveAll(name string) error {
+	return os.RemoveAll(filepath.FromSlash(name))
+}
+
+// readFile reads the golden file from the file system of the tester.
+func (g *Goldie) readFile(name string) ([]byte, error) {
+	return fs.ReadFile(g.fsys, filepath.ToSlash(name))
+}
+
+// stat returns the file info of the file from the file system of the tester.
+func (g *Goldie) stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(g.fsys, filepath.ToSlash(name))
+}
+
+// writableFS returns the file system of the tester, if golden files can be
+// written to it.
+func (g *Goldie) writableFS() (WritableFS, error) {
+	wfs, ok := g.fsys.(WritableFS)
+	if !ok {
+		return nil, fmt.Errorf("golden files cannot be written to the read-only file system %T", g.fsys)
+	}
+
+	return wfs, nil
+}
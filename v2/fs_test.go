@@ -0,0 +1,134 @@
+package goldie
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memFS is an in-memory WritableFS.
+type memFS struct {
+	fstest.MapFS
+}
+
+func newMemFS() *memFS {
+	return &memFS{MapFS: fstest.MapFS{}}
+}
+
+func (m *memFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.MapFS[name] = &fstest.MapFile{Data: data, Mode: perm}
+	return nil
+}
+
+func (m *memFS) MkdirAll(name string, perm fs.FileMode) error {
+	for dir := name; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if _, ok := m.MapFS[dir]; !ok {
+			m.MapFS[dir] = &fstest.MapFile{Mode: fs.ModeDir | perm}
+		}
+	}
+	return nil
+}
+
+func (m *memFS) Rename(oldname, newname string) error {
+	f, ok := m.MapFS[oldname]
+	if !ok {
+		return &fs.PathError{Op: "rename", Path: oldname, Err: fs.ErrNotExist}
+	}
+	delete(m.MapFS, oldname)
+	m.MapFS[newname] = f
+	return nil
+}
+
+func (m *memFS) RemoveAll(name string) error {
+	for p := range m.MapFS {
+		if p == name || strings.HasPrefix(p, name+"/") {
+			delete(m.MapFS, p)
+		}
+	}
+	return nil
+}
+
+func TestOSFSWriteFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "example.golden")
+	require.NoError(t, os.WriteFile(name, []byte("old content"), 0600))
+
+	require.NoError(t, osFS{}.WriteFile(filepath.ToSlash(name), []byte("new"), 0644))
+	assertFileContent(t, name, "new")
+
+	info, err := os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	entries, err := os.ReadDir(filepath.Dir(name))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"testdata/fs-example.golden": &fstest.MapFile{Data: []byte("abc")},
+	}
+	g := New(t, WithFS(fsys))
+
+	result, err := g.Check(t, "fs-example", []byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusMatch, result.Status)
+
+	result, err = g.Check(t, "missing", []byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusMissing, result.Status)
+
+	err = g.Update(t, "fs-example", []byte("abcd"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "read-only file system")
+
+	assert.Error(t, New(t).WithFS(nil))
+}
+
+func TestWithWritableFS(t *testing.T) {
+	savedUpdateState := *update
+	t.Cleanup(func() {
+		*update = savedUpdateState
+	})
+
+	fsys := newMemFS()
+	g := New(t, WithFS(fsys), WithReceivedFiles(true))
+
+	*update = true
+	g.Assert(t, "fs-example", []byte("abc"))
+	*update = savedUpdateState
+
+	require.Contains(t, fsys.MapFS, "testdata/fs-example.golden")
+	assert.Equal(t, []byte("abc"), fsys.MapFS["testdata/fs-example.golden"].Data)
+	assert.Equal(t, defaultFilePerms, fsys.MapFS["testdata/fs-example.golden"].Mode)
+	assertNoFile(t, "testdata/fs-example.golden")
+
+	result, err := g.Check(t, "fs-example", []byte("abcd"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusMismatch, result.Status)
+	assert.Equal(t, []byte("abcd"), fsys.MapFS["testdata/fs-example.received"].Data)
+
+	require.NoError(t, g.Accept(t, "fs-example"))
+	assert.Equal(t, []byte("abcd"), fsys.MapFS["testdata/fs-example.golden"].Data)
+	assert.NotContains(t, fsys.MapFS, "testdata/fs-example.received")
+}
+
+func TestWithFSDuplicates(t *testing.T) {
+	for name, content := range map[string]string{"one": "abc", "two": "abcd"} {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "testdata"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "testdata", "x.golden"), []byte(content), 0644))
+
+		t.Run(name, func(t *testing.T) {
+			g := New(t, WithFS(os.DirFS(dir)))
+			g.Assert(t, "x", []byte(content))
+		})
+	}
+}
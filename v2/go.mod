@@ -1,10 +1,12 @@
 module github.com/sebdah/goldie/v2
 
-go 1.12
+go 1.18
 
 require (
-	github.com/pkg/errors v0.8.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0
 	github.com/sergi/go-diff v1.0.0
 	github.com/stretchr/testify v1.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/davecgh/go-spew v1.1.0 // indirect
@@ -10,18 +10,18 @@ package goldie
 
 import (
 	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
-	"testing"
 	"time"
 
 	"github.com/pmezard/go-difflib/difflib"
-	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 const (
@@ -44,6 +44,29 @@ const (
 	// defaultDiffEngine sets which diff engine to use if not defined.
 	defaultDiffEngine = ClassicDiff
 
+	// defaultDiffWidth sets the default value for the WithDiffWidth option.
+	defaultDiffWidth = 120
+
+	// defaultDiffContext sets the default value for the WithDiffContext
+	// option.
+	defaultDiffContext = 1
+
+	// defaultDiffColor sets the default value for the WithDiffColor option.
+	defaultDiffColor = false
+
+	// defaultDiffInvisibles sets the default value for the
+	// WithDiffInvisibles option.
+	defaultDiffInvisibles = false
+
+	// defaultDiffMaxLines and defaultDiffMaxSize set the default values for
+	// the WithDiffLimits option.
+	defaultDiffMaxLines = 0
+	defaultDiffMaxSize  = 0
+
+	// defaultDiffTimeout sets the default value for the WithDiffTimeout
+	// option.
+	defaultDiffTimeout time.Duration = 0
+
 	// defaultIgnoreTemplateErrors sets the default value for the
 	// WithIgnoreTemplateErrors option.
 	defaultIgnoreTemplateErrors = false
@@ -55,13 +78,25 @@ const (
 	// defaultUseSubTestNameForDir sets the default value for the
 	// WithSubTestNameForDir option.
 	defaultUseSubTestNameForDir = false
+
+	// defaultUseReceivedFiles sets the default value for the
+	// WithReceivedFiles option.
+	defaultUseReceivedFiles = false
+
+	// defaultNamePolicy sets the default value for the WithNamePolicy option.
+	defaultNamePolicy = NameAsIs
+
+	// defaultSnapshotMode sets the default value for the WithSnapshots
+	// option.
+	defaultSnapshotMode = SnapshotOff
 )
 
 var (
 	// update determines if the actual received data should be written to the
 	// golden files or not. This should be true when you need to update the
-	// golden files, but false when actually running the tests.
-	update = flag.Bool("update", truthy(os.Getenv("GOLDIE_UPDATE")), "Update golden test file fixture")
+	// golden files, but false when actually running the tests. Setting it to a
+	// regular expression only updates the matching tests and fixtures.
+	update = updateFlag("update", truthy(os.Getenv("GOLDIE_UPDATE")), "Update golden test file fixture, optionally only those matching a regular expression")
 
 	// withTemplate determines if the templating data should be applied to the
 	// golden files or not. This should be true when you need to update the
@@ -70,14 +105,19 @@ var (
 	withTemplate = flag.Bool("template", truthy(os.Getenv("GOLDIE_TEMPLATE")), "Apply template data to golden test file fixture")
 
 	// clean determines if we should remove old golden test files in the output
-	// directory or not. This only takes effect if we are updating the golden
-	// test files.
+	// directory or not. This only takes effect if we are updating all the
+	// golden test files, i.e. not when using an update pattern.
 	clean = flag.Bool("clean", truthy(os.Getenv("GOLDIE_CLEAN")), "Clean old golden test files before writing new olds")
 
-	// ts saves the timestamp of the test run. We use ts to mark the
-	// modification time of golden file dirs for cleaning if required by
-	// `-clean` flag.
-	ts = time.Now()
+	// received determines if the actual data of missing or mismatching golden
+	// files should be written to received files next to the golden files, so
+	// that they can be reviewed and accepted later.
+	received = flag.Bool("received", truthy(os.Getenv("GOLDIE_RECEIVED")), "Write received files for mismatching golden test file fixtures")
+
+	// updateVariant determines if updating should write the most specific
+	// variant of the golden files, see WithVariants, rather than the variant
+	// currently in use.
+	updateVariant = flag.Bool("update-variant", truthy(os.Getenv("GOLDIE_UPDATE_VARIANT")), "Write the most specific variant of golden test file fixtures when updating")
 )
 
 // Goldie is the root structure for the test runner. It provides test assertions based on golden files. It's
@@ -91,25 +131,40 @@ type Goldie struct {
 	equalFn              EqualFn
 	diffEngine           DiffEngine
 	diffFn               DiffFn
+	diffOptions          diffOptions
 	ignoreTemplateErrors bool
 	useTestNameForDir    bool
 	useSubTestNameForDir bool
+	ignoreJsonPaths      [][]jsonPathStep
+	ignoreXmlPaths       []xmlPath
+	scrubbers            []Scrubber
+	useReceivedFiles     bool
+	namePolicy           NamePolicy
+	variants             []Variant
+	fsys                 fs.FS
+	snapshotMode         SnapshotMode
+	snapshotFileName     string
 }
 
 // === Create new testers ==================================
 
 // New creates a new golden file tester. If there is an issue with applying any
 // of the options, an error will be reported and t.FailNow() will be called.
-func New(t *testing.T, options ...Option) *Goldie {
+func New(t TB, options ...Option) *Goldie {
 	g := Goldie{
 		fixtureDir:           defaultFixtureDir,
 		fileNameSuffix:       defaultFileNameSuffix,
 		filePerms:            defaultFilePerms,
 		dirPerms:             defaultDirPerms,
 		diffEngine:           defaultDiffEngine,
+		diffOptions:          newDiffOptions(),
 		ignoreTemplateErrors: defaultIgnoreTemplateErrors,
 		useTestNameForDir:    defaultUseTestNameForDir,
 		useSubTestNameForDir: defaultUseSubTestNameForDir,
+		useReceivedFiles:     defaultUseReceivedFiles,
+		namePolicy:           defaultNamePolicy,
+		fsys:                 osFS{},
+		snapshotMode:         defaultSnapshotMode,
 	}
 
 	var err error
@@ -121,13 +176,49 @@ func New(t *testing.T, options ...Option) *Goldie {
 		}
 	}
 
+	if err := g.validateSnapshots(); err != nil {
+		t.Error(fmt.Errorf("could not apply option: %w", err))
+		t.FailNow()
+	}
+
+	used.watch(t)
+
 	return &g
 }
 
 // Diff generates a string that shows the difference between the actual and the
 // expected. This method could be called in your own DiffFn in case you want
 // to leverage any of the engines defined.
+//
+// The diff is neither truncated nor limited in time, see WithDiffLimits and
+// WithDiffTimeout for the diffs of assertions.
 func Diff(engine DiffEngine, actual string, expected string) (diff string) {
+	return newDiffOptions().render(engine, actual, expected)
+}
+
+// diff generates the diff between the actual and the expected with the
+// engine, using the settings of the diff options. Mismatches only caused by
+// the line endings, the trailing newline or the encoding are explained by a
+// headline. Diffs exceeding the limits are truncated. With a time budget,
+// engines based on diffmatchpatch fall back to a coarser diff when running
+// out of time, and inputs too large for the line based engines are
+// summarized instead of diffed.
+func (o diffOptions) diff(engine DiffEngine, actual string, expected string) string {
+	headline := diffHeadline(actual, expected)
+
+	if o.timeout > 0 && o.tooLarge(engine, actual, expected) {
+		return headline + o.truncate(summarizeLines(actual, expected))
+	}
+
+	return headline + o.truncate(o.render(engine, actual, expected))
+}
+
+// render generates the diff between the actual and the expected with the
+// engine, without any limits. Binary data is always shown as a hex diff, as
+// the text engines would produce garbage for it.
+func (o diffOptions) render(engine DiffEngine, actual string, expected string) (diff string) {
+	engine = o.engine(engine, actual, expected)
+
 	switch engine {
 	case Simple:
 		diff = fmt.Sprintf("Expected: %s\nGot: %s", expected, actual)
@@ -140,14 +231,32 @@ func Diff(engine DiffEngine, actual string, expected string) (diff string) {
 			FromDate: "",
 			ToFile:   "Actual",
 			ToDate:   "",
-			Context:  1,
+			Context:  o.context,
 		})
+		if o.invisibles {
+			diff = visibleChangedLines(diff)
+		}
 
 	case ColoredDiff:
-		dmp := diffmatchpatch.New()
+		dmp := o.diffMatchPatch()
 		diffs := dmp.DiffMain(actual, expected, false)
 		diff = dmp.DiffPrettyText(diffs)
 
+	case JsonDiff:
+		var ok bool
+		if diff, ok = jsonDiff(actual, expected); !ok {
+			diff = o.render(ClassicDiff, actual, expected)
+		}
+
+	case SideBySideDiff:
+		diff = sideBySideDiff(actual, expected, o)
+
+	case InlineDiff:
+		diff = inlineDiff(actual, expected, o)
+
+	case HexDiff:
+		diff = hexDiff(actual, expected, o)
+
 	default: // Simple
 		diff = fmt.Sprintf("Expected: %s\nGot: %s", expected, actual)
 	}
@@ -193,24 +302,63 @@ func meta(a interface{}) map[string]string {
 
 // Update will update the golden fixtures with the received actual data.
 //
+// The golden file is only written if its content differs from the actual
+// data, so that unchanged golden files keep their modification time.
+//
 // This method does not need to be called from code, but it's exposed so that
 // it can be explicitly called if needed. The more common approach would be to
 // update using `go test -update ./...` or `GOLDIE_UPDATE=true go test ./...`.
-func (g *Goldie) Update(t *testing.T, name string, actualData []byte) error {
-	goldenFile := g.GoldenFileName(t, name)
+func (g *Goldie) Update(t TB, name string, actualData []byte) error {
+	if g.snapshotMode != SnapshotOff {
+		return g.updateSnapshot(t, name, actualData)
+	}
+
+	goldenFile, err := g.goldenFileName(t, name)
+	if err != nil {
+		return err
+	}
+
+	goldenFile, err = g.variantToUpdate(goldenFile, actualData)
+	if err != nil {
+		return err
+	}
+	used.track(g, goldenFile)
+
+	// Read the golden file before ensureDir, which may clean its directory,
+	// to tell whether the update changed it.
+	previous, err := g.readFile(goldenFile)
+	existed := err == nil
+
+	wfs, err := g.writableFS()
+	if err != nil {
+		return err
+	}
+
 	goldenFileDir := filepath.Dir(goldenFile)
 	if err := g.ensureDir(goldenFileDir); err != nil {
 		return err
 	}
 
-	if err := os.WriteFile(goldenFile, actualData, g.filePerms); err != nil {
-		return err
+	kind := updateChanged
+	switch {
+	case !existed:
+		kind = updateCreated
+	case bytes.Equal(previous, actualData):
+		kind = updateUnchanged
+
+		// Leave the golden file and its modification time alone, unless it
+		// has just been cleaned.
+		if _, err := g.stat(goldenFile); err == nil {
+			used.updated(goldenFile, kind)
+			return nil
+		}
 	}
 
-	if err := os.Chtimes(goldenFileDir, ts, ts); err != nil {
+	if err := wfs.WriteFile(filepath.ToSlash(goldenFile), actualData, g.filePerms); err != nil {
 		return err
 	}
 
+	used.updated(goldenFile, kind)
 	return nil
 }
 
@@ -221,7 +369,7 @@ func (g *Goldie) Update(t *testing.T, name string, actualData []byte) error {
 // This method does not need to be called from code, but it's exposed so that
 // it can be explicitly called if needed. The more common approach would be to
 // update using `go test -update ./...` or `GOLDIE_UPDATE=true go test ./...`.
-func (g *Goldie) UpdateWithTemplate(t *testing.T, name string, data interface{}, actualData []byte) error {
+func (g *Goldie) UpdateWithTemplate(t TB, name string, data interface{}, actualData []byte) error {
 	meta := meta(data)
 
 	// get a reverse-sorted list of map keys so that when we loop over them,
@@ -253,44 +401,71 @@ func joinPath(path string, key interface{}) string {
 	return fmt.Sprintf("%s.%v", path, key)
 }
 
-// ensureDir will create the fixture folder if it does not already exist.
+// ensureDir will create the fixture folder if it does not already exist. When
+// cleaning, the folder is cleaned before the first golden file of the test
+// run is written to it.
 func (g *Goldie) ensureDir(loc string) error {
-	s, err := os.Stat(loc)
+	wfs, err := g.writableFS()
+	if err != nil {
+		return err
+	}
+
+	s, err := g.stat(loc)
 
 	switch {
-	case err != nil && os.IsNotExist(err):
-		// the location does not exist, so make directories to there
-		return os.MkdirAll(loc, g.dirPerms)
+	case err == nil && !s.IsDir():
+		return newErrFixtureDirectoryIsFile(loc)
+
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
 
-	case err == nil && s.IsDir() && *clean && s.ModTime().UnixNano() < ts.UnixNano():
-		if err := os.RemoveAll(loc); err != nil {
+	if *clean && updatePattern == nil {
+		if err := cleaned.clean(wfs, loc); err != nil {
 			return err
 		}
-		return os.MkdirAll(loc, g.dirPerms)
-
-	case err == nil && !s.IsDir():
-		return newErrFixtureDirectoryIsFile(loc)
 	}
 
-	return err
+	return wfs.MkdirAll(filepath.ToSlash(loc), g.dirPerms)
 }
 
 // GoldenFileName simply returns the file name of the golden file fixture.
-func (g *Goldie) GoldenFileName(t *testing.T, name string) string {
-	dir := g.fixtureDir
+//
+// When t is a *testing.F, the golden files are always stored in a directory
+// named after the fuzz target. This mirrors the layout of the fuzz corpus in
+// `testdata/fuzz/<FuzzName>` without placing golden files inside it, where
+// `go test -fuzz` would try to read them as corpus entries.
+//
+// The names are handled according to the WithNamePolicy option. If a name is
+// rejected, or the golden file is outside of the fixture directory, the file
+// name is still returned, but the assertions fail with an error.
+//
+// With WithVariants, the most specific existing variant of the golden file is
+// returned. With WithSnapshots, the snapshot file holding the fixture is
+// returned.
+func (g *Goldie) GoldenFileName(t TB, name string) string {
+	if g.snapshotMode != SnapshotOff {
+		snapshotFile, _ := g.snapshotFile(t)
+		return snapshotFile
+	}
 
-	if g.useTestNameForDir {
-		dir = filepath.Join(dir, strings.Split(t.Name(), "/")[0])
+	goldenFile, _ := g.goldenFileName(t, name)
+	return g.resolveVariant(goldenFile)
+}
+
+// flagError returns the error making the flags of the test run unusable with
+// the tester, if any: an invalid GOLDIE_UPDATE_PATTERN, or received files
+// requested for snapshots.
+func (g *Goldie) flagError() error {
+	if updatePatternErr != nil {
+		return updatePatternErr
 	}
 
-	if g.useSubTestNameForDir {
-		n := strings.Split(t.Name(), "/")
-		if len(n) > 1 {
-			dir = filepath.Join(append([]string{dir}, n[1:]...)...)
-		}
+	if *received && g.snapshotMode != SnapshotOff {
+		return errSnapshotReceived
 	}
 
-	return filepath.Join(dir, fmt.Sprintf("%s%s", name, g.fileNameSuffix))
+	return nil
 }
 
 func truthy(s string) bool {
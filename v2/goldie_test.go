@@ -5,7 +5,6 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -66,6 +65,47 @@ func TestGoldenFileName(t *testing.T) {
 	}
 }
 
+// fakeTB is a minimal custom test harness implementing TB.
+type fakeTB struct {
+	name   string
+	errors []string
+	failed bool
+}
+
+func (f *fakeTB) Helper()      {}
+func (f *fakeTB) Name() string { return f.name }
+func (f *fakeTB) FailNow()     { f.failed = true }
+
+func (f *fakeTB) Error(args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprint(args...))
+}
+
+func TestCustomTB(t *testing.T) {
+	ft := &fakeTB{name: "TestCustom/sub"}
+	g := New(ft, WithTestNameForDir(true), WithSubTestNameForDir(true))
+
+	assert.Equal(t, "testdata/TestCustom/sub/example.golden", g.GoldenFileName(ft, "example"))
+
+	g.Assert(ft, "does-not-exist", []byte("abc"))
+	assert.True(t, ft.failed)
+	assert.Len(t, ft.errors, 1)
+}
+
+func BenchmarkGoldenFileName(b *testing.B) {
+	g := New(b)
+	for i := 0; i < b.N; i++ {
+		assert.Equal(b, "testdata/example.golden", g.GoldenFileName(b, "example"))
+	}
+}
+
+func FuzzGoldenFileName(f *testing.F) {
+	g := New(f)
+	assert.Equal(f, "testdata/FuzzGoldenFileName/example.golden", g.GoldenFileName(f, "example"))
+
+	f.Add("seed")
+	f.Fuzz(func(t *testing.T, _ string) {})
+}
+
 func TestEnsureDir(t *testing.T) {
 	tests := map[string]struct {
 		dir         string
@@ -450,6 +490,26 @@ Got: Lorem ipsum dolor.`},
 				diff:   "Lorem \x1b[31mipsum \x1b[0mdolor\x1b[32m sit amet\x1b[0m.",
 			},
 		},
+		"json": {
+			actual:   `{"items":[{"price":12}],"id":1}`,
+			expected: `{"id":1.0,"items":[{"price":10}]}`,
+			engine: engine{
+				engine: JsonDiff,
+				diff:   "/items/0/price: expected 10, got 12\n",
+			},
+		},
+		"json, falling back to classic": {
+			actual:   "Lorem ipsum dolor.",
+			expected: "Lorem dolor sit amet.",
+			engine: engine{
+				engine: JsonDiff,
+				diff: `--- Expected
++++ Actual
+@@ -1 +1 @@
+-Lorem dolor sit amet.
++Lorem ipsum dolor.
+`},
+		},
 	}
 
 	for name, test := range tests {
@@ -475,7 +535,7 @@ func TestCleanFunction(t *testing.T) {
 		*update = savedUpdateState
 	})
 
-	ts = time.Now()
+	cleaned = newCleaner()
 
 	sampleData := []byte("sample data")
 	fixtureDir := "test-fixtures"
@@ -521,7 +581,7 @@ func TestCleanFunction(t *testing.T) {
 	}
 
 	*clean = true
-	ts = time.Now()
+	cleaned = newCleaner()
 
 	// The second time running go test, with -update and -clean
 	secondTests := []struct {
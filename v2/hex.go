@@ -0,0 +1,245 @@
+package goldie
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// hexRowSize is the number of bytes per row of the hex dump.
+	hexRowSize = 16
+
+	// hexContextRows is the number of rows shown before and after the row
+	// with the first differing byte.
+	hexContextRows = 2
+
+	// maxHexRanges is the maximal number of differing ranges listed in the
+	// summary of the hex diff.
+	maxHexRanges = 10
+
+	// maxNonTextRatio is the share of non-text bytes above which data is
+	// binary.
+	maxNonTextRatio = 0.3
+)
+
+// byteRange is a range of offsets, from start up to but excluding end.
+type byteRange struct {
+	start, end int
+}
+
+func (r byteRange) String() string {
+	if r.end-r.start == 1 {
+		return fmt.Sprintf("0x%08x (1 byte)", r.start)
+	}
+
+	return fmt.Sprintf("0x%08x-0x%08x (%d bytes)", r.start, r.end-1, r.end-r.start)
+}
+
+// isBinary reports whether the data is binary rather than text, i.e. it
+// contains NUL bytes or more than 30% non-text bytes: bytes that are not valid
+// UTF-8 and control characters other than white space and escape sequences.
+// Text in legacy encodings like Latin-1 is therefore still text.
+func isBinary(data string) bool {
+	if strings.IndexByte(data, 0) >= 0 {
+		return true
+	}
+
+	nonText := 0
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRuneInString(data[i:])
+
+		switch {
+		case r == utf8.RuneError && size == 1:
+			nonText++
+		case r < 0x20 && !strings.ContainsRune("\t\n\v\f\r\x1b", r), r == 0x7f:
+			nonText++
+		}
+
+		i += size
+	}
+
+	return float64(nonText) > maxNonTextRatio*float64(len(data))
+}
+
+// hexDiff compares the expected and actual data byte by byte. It summarizes
+// their sizes and the ranges of differing bytes, followed by a hex dump of the
+// rows around the first differing byte, marking the differing bytes.
+//
+//	Binary data differs: expected 32 bytes, got 32 bytes
+//	Differing ranges: 0x0000000f (1 byte)
+//	--- Expected
+//	+++ Actual
+//	-00000000  89 50 4e 47 0d 0a 1a 0a  00 00 00 0d 49 48 44 52  |.PNG........IHDR|
+//	+00000000  89 50 4e 47 0d 0a 1a 0a  00 00 00 0d 49 48 44 53  |.PNG........IHDS|
+//	                                                         ^^                  ^
+//	 00000010  00 00 00 10 00 00 00 10  08 06 00 00 00 1f f3 ff  |................|
+func hexDiff(actual, expected string, opts diffOptions) string {
+	a, b := []byte(expected), []byte(actual)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Binary data differs: expected %s, got %s\n", plural(len(a), "byte"), plural(len(b), "byte"))
+
+	ranges := differingRanges(a, b)
+	if len(ranges) == 0 {
+		return buf.String()
+	}
+
+	listed := ranges
+	if len(listed) > maxHexRanges {
+		listed = listed[:maxHexRanges]
+	}
+
+	names := make([]string, 0, len(listed))
+	for _, r := range listed {
+		names = append(names, r.String())
+	}
+	buf.WriteString("Differing ranges: " + strings.Join(names, ", "))
+	if len(ranges) > len(listed) {
+		fmt.Fprintf(&buf, " and %d more", len(ranges)-len(listed))
+	}
+	buf.WriteString("\n--- Expected\n+++ Actual\n")
+
+	size := len(a)
+	if len(b) > size {
+		size = len(b)
+	}
+
+	firstRow := ranges[0].start / hexRowSize
+	startRow := firstRow - hexContextRows
+	if startRow < 0 {
+		startRow = 0
+	}
+	endRow := firstRow + hexContextRows + 1
+	if rows := (size + hexRowSize - 1) / hexRowSize; endRow > rows {
+		endRow = rows
+	}
+
+	for row := startRow; row < endRow; row++ {
+		offset := row * hexRowSize
+		expectedRow, actualRow := hexRowData(a, offset), hexRowData(b, offset)
+
+		if bytes.Equal(expectedRow, actualRow) {
+			buf.WriteString(" " + hexRow(offset, expectedRow, nil, "", false) + "\n")
+			continue
+		}
+
+		var changed [hexRowSize]bool
+		for i := range changed {
+			changed[i] = i >= len(expectedRow) || i >= len(actualRow) || expectedRow[i] != actualRow[i]
+		}
+
+		if len(expectedRow) > 0 {
+			buf.WriteString("-" + hexRow(offset, expectedRow, changed[:], ansiRed, opts.color) + "\n")
+		}
+		if len(actualRow) > 0 {
+			buf.WriteString("+" + hexRow(offset, actualRow, changed[:], ansiGreen, opts.color) + "\n")
+		}
+		if !opts.color {
+			longer := len(expectedRow)
+			if len(actualRow) > longer {
+				longer = len(actualRow)
+			}
+			buf.WriteString(hexMarkers(changed[:longer]) + "\n")
+		}
+	}
+
+	return buf.String()
+}
+
+// differingRanges returns the ranges of offsets at which the data differs,
+// including the bytes only present in the longer data.
+func differingRanges(a, b []byte) []byteRange {
+	size := len(a)
+	if len(b) > size {
+		size = len(b)
+	}
+
+	var ranges []byteRange
+	for i := 0; i < size; i++ {
+		if i < len(a) && i < len(b) && a[i] == b[i] {
+			continue
+		}
+
+		if n := len(ranges); n > 0 && ranges[n-1].end == i {
+			ranges[n-1].end++
+		} else {
+			ranges = append(ranges, byteRange{start: i, end: i + 1})
+		}
+	}
+
+	return ranges
+}
+
+// hexRowData returns the bytes of the row starting at the offset.
+func hexRowData(data []byte, offset int) []byte {
+	if offset >= len(data) {
+		return nil
+	}
+
+	end := offset + hexRowSize
+	if end > len(data) {
+		end = len(data)
+	}
+
+	return data[offset:end]
+}
+
+// hexRow formats the row like `hexdump -C` does, with the offset, the bytes in
+// hex and the printable characters. Changed bytes are highlighted in the
+// color if colors are enabled.
+func hexRow(offset int, data []byte, changed []bool, color string, colored bool) string {
+	highlight := func(i int, text string) string {
+		if colored && i < len(changed) && changed[i] {
+			return color + text + ansiReset
+		}
+		return text
+	}
+
+	var hex, ascii strings.Builder
+	for i := 0; i < hexRowSize; i++ {
+		if i == hexRowSize/2 {
+			hex.WriteString(" ")
+		}
+		if i >= len(data) {
+			hex.WriteString("   ")
+			continue
+		}
+
+		hex.WriteString(highlight(i, fmt.Sprintf("%02x", data[i])) + " ")
+
+		c := data[i]
+		if c < 0x20 || c > 0x7e {
+			c = '.'
+		}
+		ascii.WriteString(highlight(i, string(c)))
+	}
+
+	return fmt.Sprintf("%08x  %s |%s|", offset, hex.String(), ascii.String())
+}
+
+// hexMarkers returns the line marking the changed bytes of the row above with
+// `^`, both in the hex and the character columns.
+func hexMarkers(changed []bool) string {
+	var hex, ascii strings.Builder
+	for i := 0; i < hexRowSize; i++ {
+		if i == hexRowSize/2 {
+			hex.WriteString(" ")
+		}
+
+		mark := i < len(changed) && changed[i]
+		switch {
+		case mark:
+			hex.WriteString("^^ ")
+			ascii.WriteString("^")
+		case i < len(changed):
+			hex.WriteString("   ")
+			ascii.WriteString(" ")
+		default:
+			hex.WriteString("   ")
+		}
+	}
+
+	return strings.TrimRight(fmt.Sprintf(" %8s  %s  %s", "", hex.String(), ascii.String()), " ")
+}
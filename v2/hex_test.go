@@ -0,0 +1,91 @@
+package goldie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBinary(t *testing.T) {
+	assert.False(t, isBinary(""))
+	assert.False(t, isBinary("text\twith ümlauts\r\n"))
+	assert.True(t, isBinary("nul\x00byte"))
+	assert.False(t, isBinary("Latin-1 caf\xe9 cr\xe8me br\xfbl\xe9e"))
+	assert.False(t, isBinary("\x1b[31mcolored\x1b[0m"))
+	assert.True(t, isBinary("\x89PNG\r\n\x1a\n\xff\xfe\x01\x02"))
+}
+
+func TestDifferingRanges(t *testing.T) {
+	assert.Empty(t, differingRanges([]byte("abc"), []byte("abc")))
+	assert.Equal(t, []byteRange{{1, 3}, {5, 6}, {7, 8}},
+		differingRanges([]byte("abcdefgh"), []byte("aXYdeZg")))
+}
+
+func TestHexDiff(t *testing.T) {
+	tests := map[string]struct {
+		actual   string
+		expected string
+		opts     diffOptions
+		diff     string
+	}{
+		"changed byte": {
+			actual:   "\x00abc",
+			expected: "\x00abd",
+			diff: `Binary data differs: expected 4 bytes, got 4 bytes
+Differing ranges: 0x00000003 (1 byte)
+--- Expected
++++ Actual
+-00000000  00 61 62 64                                       |.abd|
++00000000  00 61 62 63                                       |.abc|
+                    ^^                                           ^
+`,
+		},
+		"context and appended bytes": {
+			actual:   strings.Repeat("\x00", 64) + "\x01\x02",
+			expected: strings.Repeat("\x00", 64),
+			diff: `Binary data differs: expected 64 bytes, got 66 bytes
+Differing ranges: 0x00000040-0x00000041 (2 bytes)
+--- Expected
++++ Actual
+ 00000020  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+ 00000030  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
++00000040  01 02                                             |..|
+           ^^ ^^                                              ^^
+`,
+		},
+		"colored": {
+			actual:   "\x00b",
+			expected: "\x00a",
+			opts:     diffOptions{color: true},
+			diff: "Binary data differs: expected 2 bytes, got 2 bytes\n" +
+				"Differing ranges: 0x00000001 (1 byte)\n" +
+				"--- Expected\n+++ Actual\n" +
+				"-00000000  00 \x1b[31m61\x1b[0m                                             |.\x1b[31ma\x1b[0m|\n" +
+				"+00000000  00 \x1b[32m62\x1b[0m                                             |.\x1b[32mb\x1b[0m|\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.diff, hexDiff(test.actual, test.expected, test.opts))
+		})
+	}
+}
+
+func TestHexDiffRanges(t *testing.T) {
+	expected := strings.Repeat("\x00", 48)
+	actual := strings.Repeat("\x00\x01", 24)
+
+	diff := hexDiff(actual, expected, diffOptions{})
+	assert.Contains(t, diff, "Differing ranges: 0x00000001 (1 byte), 0x00000003 (1 byte)")
+	assert.Contains(t, diff, "0x00000013 (1 byte) and 14 more\n")
+}
+
+func TestBinaryDataUsesHexDiff(t *testing.T) {
+	diff := Diff(ClassicDiff, "\x00abc", "\x00abd")
+	assert.True(t, strings.HasPrefix(diff, "Binary data differs: expected 4 bytes, got 4 bytes\n"), diff)
+
+	diff = Diff(ClassicDiff, "caf\xe9\n", "cafe\n")
+	assert.Equal(t, "--- Expected\n+++ Actual\n@@ -1,2 +1,2 @@\n-cafe\n+caf\xe9\n \n", diff)
+}
@@ -0,0 +1,488 @@
+package goldie
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ignoredPlaceholder replaces the values of ignored fields, both when
+// comparing and in the stored golden files.
+const ignoredPlaceholder = "<ignored>"
+
+// xmlAttrRe matches the attributes of a raw XML start tag.
+var xmlAttrRe = regexp.MustCompile(`([^\s=<>/]+)\s*=\s*("[^"]*"|'[^']*')`)
+
+// === JSON paths ==========================================
+
+// jsonPathStep is a single step of a JSON path, selecting either an object
+// key, an array index or, if wildcard is set, all children.
+type jsonPathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJsonPath parses the subset of JSONPath supported by WithIgnorePaths:
+// `$`, `.key`, `['key']`, `[0]`, `.*` and `[*]`.
+func parseJsonPath(path string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSON path %q: must start with '$'", path)
+	}
+
+	var steps []jsonPathStep
+	for i := 1; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			if i < len(path) && path[i] == '.' {
+				return nil, fmt.Errorf("invalid JSON path %q: recursive descent is not supported", path)
+			}
+
+			end := i
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("invalid JSON path %q: empty key", path)
+			}
+
+			if path[i:end] == "*" {
+				steps = append(steps, jsonPathStep{wildcard: true})
+			} else {
+				steps = append(steps, jsonPathStep{key: path[i:end]})
+			}
+			i = end
+
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: missing ']'", path)
+			}
+
+			selector := path[i+1 : i+end]
+			switch {
+			case selector == "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				steps = append(steps, jsonPathStep{key: selector[1 : len(selector)-1]})
+
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid JSON path %q: invalid selector %q", path, selector)
+				}
+				steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			}
+			i += end + 1
+
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q: unexpected %q", path, path[i])
+		}
+	}
+
+	return steps, nil
+}
+
+// maskJsonValue replaces the values selected by steps in v with the
+// ignored placeholder. Values that don't exist are left alone.
+func maskJsonValue(v interface{}, steps []jsonPathStep) interface{} {
+	if len(steps) == 0 {
+		return ignoredPlaceholder
+	}
+
+	step, rest := steps[0], steps[1:]
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if step.wildcard {
+			for key, child := range v {
+				v[key] = maskJsonValue(child, rest)
+			}
+		} else if child, ok := v[step.key]; ok && !step.isIndex {
+			v[step.key] = maskJsonValue(child, rest)
+		}
+
+	case []interface{}:
+		if step.wildcard {
+			for i, child := range v {
+				v[i] = maskJsonValue(child, rest)
+			}
+		} else if step.isIndex && step.index < len(v) {
+			v[step.index] = maskJsonValue(v[step.index], rest)
+		}
+	}
+
+	return v
+}
+
+// maskJson decodes the JSON data, replaces all ignored fields with the
+// ignored placeholder and returns the pretty-printed result.
+func (g *Goldie) maskJson(data []byte) ([]byte, error) {
+	v, err := decodeJson(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, steps := range g.ignoreJsonPaths {
+		v = maskJsonValue(v, steps)
+	}
+
+	return encodeJson(v)
+}
+
+// maskedJsonEqual is an EqualFn that compares JSON documents structurally
+// after masking the ignored fields on both sides. An EqualFn set with
+// WithEqualFn compares the masked documents instead.
+func (g *Goldie) maskedJsonEqual(actual, expected []byte) bool {
+	a, err := g.maskJson(actual)
+	if err != nil {
+		return false
+	}
+
+	e, err := g.maskJson(expected)
+	if err != nil {
+		return false
+	}
+
+	if g.equalFn != nil {
+		return g.equalFn(a, e)
+	}
+
+	return jsonEqualFn(a, e)
+}
+
+// maskJsonText replaces the values of ignored fields in the JSON data with
+// the ignored placeholder. Unlike maskJson, the rest of the document is left
+// untouched, keeping its formatting and key order.
+func (g *Goldie) maskJsonText(data []byte) ([]byte, error) {
+	var spans [][2]int64
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := g.findIgnoredJson(dec, nil, &spans); err != nil {
+		return nil, err
+	}
+
+	var (
+		buf         bytes.Buffer
+		pos         int64
+		placeholder = strconv.Quote(ignoredPlaceholder)
+	)
+	for _, s := range spans {
+		buf.Write(data[pos:s[0]])
+		buf.WriteString(placeholder)
+		pos = s[1]
+	}
+	buf.Write(data[pos:])
+
+	return buf.Bytes(), nil
+}
+
+// findIgnoredJson reads the next value from the decoder, at the path given
+// by steps, and appends the offsets of the ignored values within it to
+// spans.
+func (g *Goldie) findIgnoredJson(dec *json.Decoder, steps []jsonPathStep, spans *[][2]int64) error {
+	if g.isIgnoredJson(steps) {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+
+		end := dec.InputOffset()
+		*spans = append(*spans, [2]int64{end - int64(len(bytes.TrimSpace(raw))), end})
+		return nil
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			step := jsonPathStep{key: key.(string)}
+			if err := g.findIgnoredJson(dec, append(steps[:len(steps):len(steps)], step), spans); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			step := jsonPathStep{index: i, isIndex: true}
+			if err := g.findIgnoredJson(dec, append(steps[:len(steps):len(steps)], step), spans); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	}
+
+	return err
+}
+
+// isIgnoredJson reports whether the value at the path given by steps is
+// selected by any of the ignored JSON paths.
+func (g *Goldie) isIgnoredJson(steps []jsonPathStep) bool {
+	for _, path := range g.ignoreJsonPaths {
+		if len(path) != len(steps) {
+			continue
+		}
+
+		matched := true
+		for i, step := range path {
+			switch {
+			case step.wildcard:
+			case step.isIndex:
+				matched = matched && steps[i].isIndex && steps[i].index == step.index
+			default:
+				matched = matched && !steps[i].isIndex && steps[i].key == step.key
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maskedJsonTextEqual is an EqualFn that compares JSON documents byte by
+// byte, or with the EqualFn set with WithEqualFn, after masking the ignored
+// fields on both sides with maskJsonText.
+func (g *Goldie) maskedJsonTextEqual(actual, expected []byte) bool {
+	a, err := g.maskJsonText(actual)
+	if err != nil {
+		return false
+	}
+
+	e, err := g.maskJsonText(expected)
+	if err != nil {
+		return false
+	}
+
+	return g.equal(a, e)
+}
+
+// === XML paths ===========================================
+
+// xmlPathStep is a single step of an XML path, matching an element by its
+// local name or, if name is `*`, any element. A descendant step may be
+// preceded by any number of other elements.
+type xmlPathStep struct {
+	name       string
+	descendant bool
+}
+
+// xmlPath selects either the content of elements or, if attr is set, the
+// attributes of elements.
+type xmlPath struct {
+	steps []xmlPathStep
+	attr  string
+}
+
+// parseXmlPath parses the subset of XPath supported by WithIgnorePaths:
+// `/a/b`, `//b`, `*` and a trailing `@attr` or `@*`.
+func parseXmlPath(path string) (xmlPath, error) {
+	var p xmlPath
+	if !strings.HasPrefix(path, "/") {
+		return p, fmt.Errorf("invalid XML path %q: must start with '/'", path)
+	}
+
+	for rest := path; rest != ""; {
+		descendant := strings.HasPrefix(rest, "//")
+		rest = strings.TrimLeft(rest, "/")
+
+		end := strings.IndexByte(rest, '/')
+		if end < 0 {
+			end = len(rest)
+		}
+
+		name := rest[:end]
+		rest = rest[end:]
+
+		switch {
+		case name == "":
+			return p, fmt.Errorf("invalid XML path %q: empty step", path)
+
+		case strings.HasPrefix(name, "@"):
+			if rest != "" || len(name) == 1 {
+				return p, fmt.Errorf("invalid XML path %q: attribute must be the last step", path)
+			}
+			if descendant {
+				p.steps = append(p.steps, xmlPathStep{name: "*", descendant: true})
+			}
+			p.attr = name[1:]
+
+		case strings.ContainsAny(name, "[]()=@"):
+			return p, fmt.Errorf("invalid XML path %q: predicates are not supported", path)
+
+		default:
+			p.steps = append(p.steps, xmlPathStep{name: name, descendant: descendant})
+		}
+	}
+
+	if len(p.steps) == 0 {
+		return p, fmt.Errorf("invalid XML path %q: no element selected", path)
+	}
+
+	return p, nil
+}
+
+// match reports whether the path selects the element with the given stack
+// of ancestor names, starting at the root element.
+func (p xmlPath) match(stack []string) bool {
+	return matchXmlSteps(p.steps, stack)
+}
+
+func matchXmlSteps(steps []xmlPathStep, stack []string) bool {
+	if len(steps) == 0 {
+		return len(stack) == 0
+	}
+
+	step := steps[0]
+	if !step.descendant {
+		return len(stack) > 0 && matchXmlName(step.name, stack[0]) && matchXmlSteps(steps[1:], stack[1:])
+	}
+
+	for i := range stack {
+		if matchXmlName(step.name, stack[i]) && matchXmlSteps(steps[1:], stack[i+1:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchXmlName(pattern, name string) bool {
+	return pattern == "*" || pattern == name
+}
+
+// maskXml replaces the content of ignored elements and the values of
+// ignored attributes with the ignored placeholder. The rest of the document
+// is left untouched.
+func (g *Goldie) maskXml(data []byte) ([]byte, error) {
+	type span struct {
+		start, end int64
+	}
+
+	var (
+		spans  []span
+		stack  []string
+		starts []int64
+		masked []bool
+	)
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		before := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, tok.Name.Local)
+			after := dec.InputOffset()
+
+			mask := false
+			for _, p := range g.ignoreXmlPaths {
+				if !p.match(stack) {
+					continue
+				}
+
+				if p.attr == "" {
+					mask = true
+					continue
+				}
+
+				for _, m := range xmlAttrRe.FindAllSubmatchIndex(data[before:after], -1) {
+					name := string(data[before+int64(m[2]) : before+int64(m[3])])
+					if i := strings.IndexByte(name, ':'); i >= 0 {
+						name = name[i+1:]
+					}
+					if matchXmlName(p.attr, name) {
+						spans = append(spans, span{before + int64(m[4]) + 1, before + int64(m[5]) - 1})
+					}
+				}
+			}
+
+			starts = append(starts, after)
+			masked = append(masked, mask)
+
+		case xml.EndElement:
+			last := len(stack) - 1
+			if masked[last] && before > starts[last] {
+				spans = append(spans, span{starts[last], before})
+			}
+
+			stack, starts, masked = stack[:last], starts[:last], masked[:last]
+		}
+	}
+
+	// Replace outer spans first, dropping the spans nested within them.
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	var (
+		buf         bytes.Buffer
+		pos         int64
+		placeholder = xmlEscape(ignoredPlaceholder)
+	)
+	for _, s := range spans {
+		if s.start < pos {
+			continue
+		}
+		buf.Write(data[pos:s.start])
+		buf.WriteString(placeholder)
+		pos = s.end
+	}
+	buf.Write(data[pos:])
+
+	return buf.Bytes(), nil
+}
+
+// maskedXmlEqual is an EqualFn that compares XML documents byte by byte, or
+// with the EqualFn set with WithEqualFn, after masking the ignored elements
+// and attributes on both sides.
+func (g *Goldie) maskedXmlEqual(actual, expected []byte) bool {
+	a, err := g.maskXml(actual)
+	if err != nil {
+		return false
+	}
+
+	e, err := g.maskXml(expected)
+	if err != nil {
+		return false
+	}
+
+	return g.equal(a, e)
+}
+
+// xmlEscape escapes s for use in XML character data and attribute values.
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
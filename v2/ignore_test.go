@@ -0,0 +1,147 @@
+package goldie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithIgnorePaths(t *testing.T) {
+	tests := map[string]struct {
+		path string
+		err  bool
+	}{
+		"json key":               {path: "$.meta.requestId"},
+		"json wildcard":          {path: "$.items[*].createdAt"},
+		"json quoted key":        {path: "$['a.b'][0]"},
+		"json recursive descent": {path: "$..id", err: true},
+		"json invalid selector":  {path: "$.items[x]", err: true},
+		"json unterminated":      {path: "$.items[0", err: true},
+		"xml element":            {path: "/response/meta/requestId"},
+		"xml descendant":         {path: "//createdAt"},
+		"xml attribute":          {path: "//item/@id"},
+		"xml attribute not last": {path: "/a/@id/b", err: true},
+		"xml predicate":          {path: "/a/b[1]", err: true},
+		"xml without element":    {path: "/@id", err: true},
+		"unknown syntax":         {path: "meta.requestId", err: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := &Goldie{}
+			err := g.WithIgnorePaths(test.path)
+			assert.Equal(t, test.err, err != nil)
+		})
+	}
+}
+
+func TestMaskJson(t *testing.T) {
+	g := &Goldie{}
+	err := g.WithIgnorePaths("$.meta.requestId", "$.items[*].createdAt", "$.missing.key", "$.list[1]")
+	require.NoError(t, err)
+
+	actual, err := g.maskJson([]byte(`{
+		"meta": {"requestId": "abc", "version": 1},
+		"items": [{"id": 1, "createdAt": "now"}, {"id": 2}],
+		"list": [1, 2, 3]
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, `{
+  "items": [
+    {
+      "createdAt": "<ignored>",
+      "id": 1
+    },
+    {
+      "id": 2
+    }
+  ],
+  "list": [
+    1,
+    "<ignored>",
+    3
+  ],
+  "meta": {
+    "requestId": "<ignored>",
+    "version": 1
+  }
+}`, string(actual))
+
+	assert.True(t, g.maskedJsonEqual(
+		[]byte(`{"meta":{"requestId":"abc","version":1}}`),
+		[]byte(`{"meta":{"requestId":"def","version":1}}`),
+	))
+	assert.False(t, g.maskedJsonEqual(
+		[]byte(`{"meta":{"requestId":"abc","version":1}}`),
+		[]byte(`{"meta":{"requestId":"abc","version":2}}`),
+	))
+}
+
+func TestMaskJsonText(t *testing.T) {
+	g := &Goldie{}
+	err := g.WithIgnorePaths("$.meta.requestId", "$.items[*].createdAt", "$.missing.key", "$.list[1]")
+	require.NoError(t, err)
+
+	actual, err := g.maskJsonText([]byte(`{
+  "meta": {"requestId": {"id": "abc"}, "version": 1},
+  "list": [1, 2, 3],
+  "items": [{"id": 1, "createdAt": "now"}, {"id": 2}]
+}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, `{
+  "meta": {"requestId": "<ignored>", "version": 1},
+  "list": [1, "<ignored>", 3],
+  "items": [{"id": 1, "createdAt": "<ignored>"}, {"id": 2}]
+}`, string(actual))
+
+	assert.True(t, g.maskedJsonTextEqual(
+		[]byte(`{"meta":{"requestId":"abc","version":1}}`),
+		[]byte(`{"meta":{"requestId":"def","version":1}}`),
+	))
+	assert.False(t, g.maskedJsonTextEqual(
+		[]byte(`{"meta":{"requestId":"abc","version":1}}`),
+		[]byte(`{"meta":{"version":1,"requestId":"abc"}}`),
+	))
+}
+
+func TestMaskXml(t *testing.T) {
+	g := &Goldie{}
+	err := g.WithIgnorePaths("/response/meta/requestId", "//item/@id", "//createdAt")
+	require.NoError(t, err)
+
+	actual, err := g.maskXml([]byte(`<response>
+  <meta>
+    <requestId>abc</requestId>
+    <version>1</version>
+  </meta>
+  <item id="1" name="a"><createdAt><day>1</day></createdAt></item>
+  <item id='2'/>
+  <requestId>kept</requestId>
+</response>`))
+	require.NoError(t, err)
+
+	assert.Equal(t, `<response>
+  <meta>
+    <requestId>&lt;ignored&gt;</requestId>
+    <version>1</version>
+  </meta>
+  <item id="&lt;ignored&gt;" name="a"><createdAt>&lt;ignored&gt;</createdAt></item>
+  <item id='&lt;ignored&gt;'/>
+  <requestId>kept</requestId>
+</response>`, string(actual))
+
+	assert.True(t, g.maskedXmlEqual(
+		[]byte(`<response><meta><requestId>abc</requestId></meta></response>`),
+		[]byte(`<response><meta><requestId>def</requestId></meta></response>`),
+	))
+	assert.False(t, g.maskedXmlEqual(
+		[]byte(`<response><meta><version>1</version></meta></response>`),
+		[]byte(`<response><meta><version>2</version></meta></response>`),
+	))
+
+	_, err = g.maskXml([]byte(`<response>`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package goldie
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Plain-text markers of the inline diff, enclosing removed and added words.
+const (
+	inlineRemovedStart = "[-"
+	inlineRemovedEnd   = "-]"
+	inlineAddedStart   = "{+"
+	inlineAddedEnd     = "+}"
+)
+
+// inlineWriter renders the lines of an inline diff.
+type inlineWriter struct {
+	buf  strings.Builder
+	opts diffOptions
+}
+
+// inlineDiff produces a unified diff of the lines of the expected and actual
+// data, with the configured number of context lines. A changed line is merged
+// with the line replacing it into a single line prefixed with `~`, marking the
+// removed and added words. Words that only differ in a few characters are
+// marked character by character instead.
+//
+//	--- Expected
+//	+++ Actual
+//	@@ -1,4 +1,3 @@
+//	 name    value
+//	~colo[-u-]r  {+dark +}red
+//	~size    1[-0-]{+2+}
+//	-weight  3
+func inlineDiff(actual, expected string, opts diffOptions) string {
+	a := splitLines(expected)
+	b := splitLines(actual)
+
+	w := &inlineWriter{opts: opts}
+	w.buf.WriteString("--- Expected\n+++ Actual\n")
+
+	for _, group := range difflib.NewMatcher(a, b).GetGroupedOpCodes(opts.context) {
+		first, last := group[0], group[len(group)-1]
+		fmt.Fprintf(&w.buf, "@@ -%s +%s @@\n",
+			unifiedRange(first.I1, last.I2), unifiedRange(first.J1, last.J2))
+
+		for _, op := range group {
+			i, j := op.I1, op.J1
+
+			switch op.Tag {
+			case 'e':
+				for ; i < op.I2; i++ {
+					w.buf.WriteString(" " + a[i] + "\n")
+				}
+
+			case 'r':
+				for ; i < op.I2 && j < op.J2; i, j = i+1, j+1 {
+					w.buf.WriteString("~")
+					w.words(opts.showInvisibles(a[i]), opts.showInvisibles(b[j]))
+					w.buf.WriteString("\n")
+				}
+				fallthrough
+
+			case 'd', 'i':
+				for ; i < op.I2; i++ {
+					w.line("-"+opts.showInvisibles(a[i]), ansiRed)
+				}
+				for ; j < op.J2; j++ {
+					w.line("+"+opts.showInvisibles(b[j]), ansiGreen)
+				}
+			}
+		}
+	}
+
+	return w.buf.String()
+}
+
+// unifiedRange formats the range of lines of a hunk header, like the unified
+// diff of difflib does.
+func unifiedRange(start, stop int) string {
+	beginning, length := start+1, stop-start
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", beginning-1)
+	case 1:
+		return fmt.Sprintf("%d", beginning)
+	}
+
+	return fmt.Sprintf("%d,%d", beginning, length)
+}
+
+// words writes the merged line, marking the removed and added words.
+func (w *inlineWriter) words(expected, actual string) {
+	a := splitWords(expected)
+	b := splitWords(actual)
+
+	for _, op := range difflib.NewMatcherWithJunk(a, b, false, nil).GetOpCodes() {
+		removed := strings.Join(a[op.I1:op.I2], "")
+		added := strings.Join(b[op.J1:op.J2], "")
+
+		switch {
+		case op.Tag == 'e':
+			w.buf.WriteString(removed)
+		case op.Tag == 'r' && op.I2-op.I1 == 1 && op.J2-op.J1 == 1 && similarWords(removed, added):
+			w.characters(removed, added)
+		default:
+			w.removed(removed)
+			w.added(added)
+		}
+	}
+}
+
+// characters writes the merged word, marking the removed and added
+// characters.
+func (w *inlineWriter) characters(expected, actual string) {
+	dmp := w.opts.diffMatchPatch()
+	for _, d := range dmp.DiffMain(expected, actual, false) {
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			w.buf.WriteString(d.Text)
+		case diffmatchpatch.DiffDelete:
+			w.removed(d.Text)
+		case diffmatchpatch.DiffInsert:
+			w.added(d.Text)
+		}
+	}
+}
+
+// line writes a whole removed or added line, in the color if colors are
+// enabled.
+func (w *inlineWriter) line(text, color string) {
+	if w.opts.color {
+		text = color + text + ansiReset
+	}
+	w.buf.WriteString(text + "\n")
+}
+
+// removed writes the text marked as removed.
+func (w *inlineWriter) removed(text string) {
+	w.mark(text, inlineRemovedStart, inlineRemovedEnd, ansiRed)
+}
+
+// added writes the text marked as added.
+func (w *inlineWriter) added(text string) {
+	w.mark(text, inlineAddedStart, inlineAddedEnd, ansiGreen)
+}
+
+// mark writes the text enclosed in the plain-text markers, or in the color if
+// colors are enabled.
+func (w *inlineWriter) mark(text, start, end, color string) {
+	if text == "" {
+		return
+	}
+
+	if w.opts.color {
+		start, end = color, ansiReset
+	}
+	w.buf.WriteString(start + text + end)
+}
+
+// splitWords splits the line into words, runs of white space and single other
+// characters, like punctuation. Bytes that are not valid UTF-8 are kept as
+// single characters.
+func splitWords(line string) []string {
+	var words []string
+
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		j := i + size
+		switch {
+		case isWordRune(r):
+			j = skipRunes(line, j, isWordRune)
+		case unicode.IsSpace(r):
+			j = skipRunes(line, j, unicode.IsSpace)
+		}
+
+		words = append(words, line[i:j])
+		i = j
+	}
+
+	return words
+}
+
+// skipRunes returns the index of the first rune of the line from i on that
+// does not satisfy f.
+func skipRunes(line string, i int, f func(rune) bool) int {
+	for i < len(line) {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if !f(r) {
+			break
+		}
+		i += size
+	}
+
+	return i
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// similarWords reports whether the words share at least half of their
+// characters as common prefix and suffix, so that a diff of their characters
+// is easier to read than replacing the whole word. Words with bytes that are
+// not valid UTF-8 are never similar, as the character diff can't keep them.
+func similarWords(a, b string) bool {
+	if !utf8.ValidString(a) || !utf8.ValidString(b) {
+		return false
+	}
+
+	ra, rb := []rune(a), []rune(b)
+
+	shorter, longer := len(ra), len(rb)
+	if shorter > longer {
+		shorter, longer = longer, shorter
+	}
+
+	common := 0
+	for common < shorter && ra[common] == rb[common] {
+		common++
+	}
+	for k := 1; common < shorter && ra[len(ra)-k] == rb[len(rb)-k]; k++ {
+		common++
+	}
+
+	return 2*common >= longer
+}
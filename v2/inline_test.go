@@ -0,0 +1,99 @@
+package goldie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineDiff(t *testing.T) {
+	tests := map[string]struct {
+		actual   string
+		expected string
+		opts     diffOptions
+		diff     string
+	}{
+		"words and characters": {
+			actual:   "name    value\ncolor  dark red\nsize    12\n",
+			expected: "name    value\ncolour  red\nsize    10\nweight  3\n",
+			opts:     diffOptions{context: 1},
+			diff: `--- Expected
++++ Actual
+@@ -1,4 +1,3 @@
+ name    value
+~colo[-u-]r  {+dark +}red
+~size    1[-0-]{+2+}
+-weight  3
+`,
+		},
+		"context": {
+			actual:   "a\nb\nc\nd\ne\nf\ng\nh\n",
+			expected: "a\nB\nc\nd\ne\nf\nG\nh\nextra\n",
+			opts:     diffOptions{context: 1},
+			diff: `--- Expected
++++ Actual
+@@ -1,3 +1,3 @@
+ a
+~[-B-]{+b+}
+ c
+@@ -6,4 +6,3 @@
+ f
+~[-G-]{+g+}
+ h
+-extra
+`,
+		},
+		"no context": {
+			actual:   "a\nb\nc\n",
+			expected: "a\nB\nc\n",
+			opts:     diffOptions{context: 0},
+			diff: `--- Expected
++++ Actual
+@@ -2 +2 @@
+~[-B-]{+b+}
+`,
+		},
+		"invisibles": {
+			actual:   "a\tb",
+			expected: "a b ",
+			opts:     diffOptions{context: 1, invisibles: true},
+			diff: `--- Expected
++++ Actual
+@@ -1 +1 @@
+~a[- -]{+→+}b[-·-]
+`,
+		},
+		"Latin-1": {
+			actual:   "colour: gr\xfcn\nsize: gro\xdf\n",
+			expected: "colour: gr\xe4n\nsize: gro\xdf\n",
+			opts:     diffOptions{context: 0},
+			diff:     "--- Expected\n+++ Actual\n@@ -1 +1 @@\n~colour: gr[-\xe4-]{+\xfc+}n\n",
+		},
+		"colored": {
+			actual:   "the quick brown fox\nnew",
+			expected: "the quack red fox",
+			opts:     diffOptions{context: 1, color: true},
+			diff: "--- Expected\n+++ Actual\n@@ -1 +1,2 @@\n" +
+				"~the qu\x1b[31ma\x1b[0m\x1b[32mi\x1b[0mck \x1b[31mred\x1b[0m\x1b[32mbrown\x1b[0m fox\n" +
+				"\x1b[32m+new\x1b[0m\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.diff, inlineDiff(test.actual, test.expected, test.opts))
+		})
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	assert.Equal(t, []string{"foo_bar", "  ", "=", " ", "42", ";"}, splitWords("foo_bar  = 42;"))
+	assert.Equal(t, []string{"gr", "\xfc", "n", ",", " ", "\xe4", "b"}, splitWords("gr\xfcn, \xe4b"))
+	assert.Empty(t, splitWords(""))
+}
+
+func TestWithDiffContext(t *testing.T) {
+	g := New(t, WithDiffEngine(InlineDiff), WithDiffContext(0))
+	assert.Equal(t, 0, g.diffOptions.context)
+	assert.Error(t, g.WithDiffContext(-1))
+}
@@ -1,8 +1,9 @@
 package goldie
 
 import (
+	"io/fs"
 	"os"
-	"testing"
+	"time"
 )
 
 // Compile time assurance
@@ -13,14 +14,33 @@ var _ OptionProcessor = (*Goldie)(nil)
 // options to an OptionProcessor.
 type Option func(OptionProcessor) error
 
+// TB is the subset of testing.TB that goldie relies on. *testing.T,
+// *testing.B and *testing.F all satisfy it, and so does any custom test
+// harness implementing these methods.
+type TB interface {
+	Helper()
+	Name() string
+	Error(args ...interface{})
+	FailNow()
+}
+
 // Tester defines the methods that any golden tester should support.
 type Tester interface {
-	Assert(t *testing.T, name string, actualData []byte)
-	AssertJson(t *testing.T, name string, actualJsonData interface{})
-	AssertXml(t *testing.T, name string, actualXmlData interface{})
-	AssertWithTemplate(t *testing.T, name string, data interface{}, actualData []byte)
-	Update(t *testing.T, name string, actualData []byte) error
-	GoldenFileName(t *testing.T, name string) string
+	Assert(t TB, name string, actualData []byte)
+	AssertAuto(t TB, actualData []byte)
+	AssertJson(t TB, name string, actualJsonData interface{})
+	AssertJsonBytes(t TB, name string, actualJson []byte)
+	AssertXml(t TB, name string, actualXmlData interface{})
+	AssertYaml(t TB, name string, actualYamlData interface{})
+	AssertYamlBytes(t TB, name string, actualYaml []byte)
+	AssertWithTemplate(t TB, name string, data interface{}, actualData []byte)
+	Check(t TB, name string, actualData []byte) (*Result, error)
+	CheckAuto(t TB, actualData []byte) (*Result, error)
+	CheckWithTemplate(t TB, name string, data interface{}, actualData []byte) (*Result, error)
+	Update(t TB, name string, actualData []byte) error
+	GoldenFileName(t TB, name string) string
+	ReceivedFileName(t TB, name string) string
+	Accept(t TB, name string) error
 }
 
 // EqualFn compares if actual and expected are equal.
@@ -60,6 +80,67 @@ const (
 	// Expected: <data>
 	// Got: <data>
 	Simple
+
+	// JsonDiff compares the actual and expected data as JSON documents and
+	// lists every difference with its JSON Pointer path. Objects are compared
+	// regardless of key order and numbers are compared numerically. If either
+	// side is not valid JSON, it falls back to ClassicDiff.
+	//
+	//		/items/3/price: expected 10, got 12
+	//		/meta/requestId: missing, expected "abc"
+	//		/tags: expected 2 elements, got 3
+	//		/tags/2: unexpected, got "new"
+	//
+	JsonDiff
+
+	// SideBySideDiff shows the expected and actual data in two aligned
+	// columns with line numbers, which suits short but wide outputs like
+	// tables. The columns fill the width of the terminal, which the COLUMNS
+	// environment variable overrides. Without a terminal, e.g. in CI, the
+	// width set with WithDiffWidth is used.
+	// Changed characters are marked with `^`, or highlighted with
+	// WithDiffColor.
+	//
+	//		  Expected                       Actual
+	//		1 name    value                1 name    value
+	//		2 colour  red                | 2 color   red
+	//		      ^                               ^
+	//
+	SideBySideDiff
+
+	// InlineDiff produces a unified diff with the number of context lines set
+	// with WithDiffContext. Each changed line is merged with the line
+	// replacing it into a single line prefixed with `~`, marking removed
+	// words with `[-...-]` and added words with `{+...+}`, or in red and
+	// green with WithDiffColor. Words differing in a few characters only are
+	// marked character by character.
+	//
+	//		--- Expected
+	//		+++ Actual
+	//		@@ -1,4 +1,3 @@
+	//		 name    value
+	//		~colo[-u-]r  {+dark +}red
+	//		~size    1[-0-]{+2+}
+	//		-weight  3
+	//
+	InlineDiff
+
+	// HexDiff compares the data byte by byte. It summarizes the sizes and the
+	// ranges of differing bytes, followed by a hex dump of the rows around the
+	// first differing byte. Binary data, i.e. data containing NUL bytes or
+	// mostly bytes that are neither valid UTF-8 nor text, is always shown as
+	// a hex diff, whatever the engine.
+	//
+	//		Binary data differs: expected 32 bytes, got 32 bytes
+	//		Differing ranges: 0x0000000f (1 byte)
+	//		--- Expected
+	//		+++ Actual
+	//		-00000000  89 50 4e 47 0d 0a 1a 0a  00 00 00 0d 49 48 44 52  |.PNG........IHDR|
+	//		+00000000  89 50 4e 47 0d 0a 1a 0a  00 00 00 0d 49 48 44 53  |.PNG........IHDS|
+	//		                                                         ^^                  ^
+	//		 00000010  00 00 00 10 00 00 00 10  08 06 00 00 00 1f f3 ff  |................|
+	//
+	HexDiff
 )
 
 // OptionProcessor defines the functions that can be called to set values for
@@ -77,6 +158,20 @@ type OptionProcessor interface {
 	WithIgnoreTemplateErrors(ignoreErrors bool) error
 	WithTestNameForDir(use bool) error
 	WithSubTestNameForDir(use bool) error
+	WithIgnorePaths(paths ...string) error
+	WithScrubber(scrubbers ...Scrubber) error
+	WithReceivedFiles(use bool) error
+	WithNamePolicy(policy NamePolicy) error
+	WithVariants(variants ...Variant) error
+	WithFS(fsys fs.FS) error
+	WithSnapshots(mode SnapshotMode) error
+	WithSnapshotFile(name string) error
+	WithDiffWidth(width int) error
+	WithDiffColor(color bool) error
+	WithDiffContext(lines int) error
+	WithDiffLimits(maxLines, maxSize int) error
+	WithDiffTimeout(timeout time.Duration) error
+	WithDiffInvisibles(show bool) error
 }
 
 // === OptionProcessor ===============================
@@ -184,3 +279,192 @@ func WithSubTestNameForDir(use bool) Option {
 		return o.WithSubTestNameForDir(use)
 	}
 }
+
+// WithIgnorePaths excludes fields from the comparison of AssertJson,
+// AssertJsonBytes and AssertXml. Ignored fields are replaced with a stable
+// placeholder in the stored golden file.
+//
+// Paths starting with `$` select JSON values, using a subset of JSONPath
+// (`$.meta.requestId`, `$.items[*].createdAt`, `$['a.b'][0]`). Paths
+// starting with `/` select XML elements or attributes, using a subset of
+// XPath (`/response/meta/requestId`, `//createdAt`, `//item/@id`).
+//noinspection GoUnusedExportedFunction
+func WithIgnorePaths(paths ...string) Option {
+	return func(o OptionProcessor) error {
+		return o.WithIgnorePaths(paths...)
+	}
+}
+
+// WithScrubber adds scrubbers that rewrite volatile content in the actual
+// data into stable tokens, before it's compared with or written to the
+// golden file. Scrubbers are applied in the order they are added. See
+// DefaultScrubbers for the built-in scrubbers.
+//
+// Default value is no scrubbers.
+//noinspection GoUnusedExportedFunction
+func WithScrubber(scrubbers ...Scrubber) Option {
+	return func(o OptionProcessor) error {
+		return o.WithScrubber(scrubbers...)
+	}
+}
+
+// WithReceivedFiles enables the approval workflow. Instead of failing only,
+// missing or mismatching golden files will have the actual data written to a
+// `.received` file next to the golden file, which can be promoted with
+// Accept or AcceptAll. Stale received files are removed when the golden file
+// matches. The workflow can also be enabled with the `-received` flag.
+//
+// Default value is false.
+//noinspection GoUnusedExportedFunction
+func WithReceivedFiles(use bool) Option {
+	return func(o OptionProcessor) error {
+		return o.WithReceivedFiles(use)
+	}
+}
+
+// WithNamePolicy sets how fixture names, and the test names used for the
+// fixture directories, that are not portable file names are handled. See
+// NamePolicy for the available policies.
+//
+// Default: NameAsIs
+//noinspection GoUnusedExportedFunction
+func WithNamePolicy(policy NamePolicy) Option {
+	return func(o OptionProcessor) error {
+		return o.WithNamePolicy(policy)
+	}
+}
+
+// WithVariants makes the golden files specific to the given variants of the
+// environment, e.g. `WithVariants(GOOS, GoVersionMinor)`. Assertions use the
+// most specific existing variant, e.g. `example.linux.go1.22.golden`,
+// `example.linux.golden` or `example.go1.22.golden`, falling back to the
+// generic `example.golden`. Updating rewrites the variant in use. With the
+// `-update-variant` flag, updating writes the most specific variant instead,
+// but only if the actual data differs from the golden file it would fall back
+// to.
+//
+// Default: no variants.
+//noinspection GoUnusedExportedFunction
+func WithVariants(variants ...Variant) Option {
+	return func(o OptionProcessor) error {
+		return o.WithVariants(variants...)
+	}
+}
+
+// WithFS sets the file system the golden files are read from, e.g. an
+// embed.FS. The golden file paths, starting with the fixture directory, are
+// looked up relative to the root of the file system. To update golden files,
+// the file system must implement WritableFS.
+//
+// Default: the working directory, through the os package.
+//noinspection GoUnusedExportedFunction
+func WithFS(fsys fs.FS) Option {
+	return func(o OptionProcessor) error {
+		return o.WithFS(fsys)
+	}
+}
+
+// WithSnapshots stores the fixtures in snapshot files holding many fixtures
+// each, instead of a golden file per fixture: one per top-level test with
+// SnapshotPerTest, or one with the name given by WithSnapshotFile with
+// SnapshotPerFile. Updating only rewrites the changed entries. Received files
+// and variants are not supported for snapshots, and combining them is an
+// error.
+//
+// Default: SnapshotOff
+//noinspection GoUnusedExportedFunction
+func WithSnapshots(mode SnapshotMode) Option {
+	return func(o OptionProcessor) error {
+		return o.WithSnapshots(mode)
+	}
+}
+
+// WithSnapshotFile stores the fixtures of all tests in one snapshot file with
+// the given name in the fixture directory, e.g. `WithSnapshotFile("render")`
+// for `testdata/render.snap`. It implies SnapshotPerFile. The name must not
+// leave the fixture directory, and it's subject to WithNamePolicy, just like
+// fixture names.
+//
+// Default: no snapshot file.
+//noinspection GoUnusedExportedFunction
+func WithSnapshotFile(name string) Option {
+	return func(o OptionProcessor) error {
+		return o.WithSnapshotFile(name)
+	}
+}
+
+// WithDiffWidth sets the width of the diff output used by SideBySideDiff if the
+// width of the terminal can't be detected, e.g. in CI, and the COLUMNS
+// environment variable is not set.
+//
+// Default: 120
+//noinspection GoUnusedExportedFunction
+func WithDiffWidth(width int) Option {
+	return func(o OptionProcessor) error {
+		return o.WithDiffWidth(width)
+	}
+}
+
+// WithDiffColor enables ANSI colors in the diff engines supporting both
+// colored and plain output, like SideBySideDiff, InlineDiff and HexDiff.
+//
+// Default: false
+//noinspection GoUnusedExportedFunction
+func WithDiffColor(color bool) Option {
+	return func(o OptionProcessor) error {
+		return o.WithDiffColor(color)
+	}
+}
+
+// WithDiffContext sets the number of unchanged lines shown around the changed
+// lines by ClassicDiff and InlineDiff.
+//
+// Default: 1
+//noinspection GoUnusedExportedFunction
+func WithDiffContext(lines int) Option {
+	return func(o OptionProcessor) error {
+		return o.WithDiffContext(lines)
+	}
+}
+
+// WithDiffLimits limits the diff output to at most maxLines lines and maxSize
+// bytes, so that a mismatch of a large fixture does not flood the test log.
+// The truncated diff ends with a summary like `... 3 more hunks omitted`. Zero
+// disables a limit.
+//
+// Default: no limits.
+//noinspection GoUnusedExportedFunction
+func WithDiffLimits(maxLines, maxSize int) Option {
+	return func(o OptionProcessor) error {
+		return o.WithDiffLimits(maxLines, maxSize)
+	}
+}
+
+// WithDiffTimeout sets the time budget of the diff algorithm, so that huge
+// inputs cannot hang a test. Engines based on diffmatchpatch, like
+// ColoredDiff, produce a coarser diff when running out of time. The line
+// based engines, like ClassicDiff, summarize data of more than 10000 lines by
+// its first differing line instead of diffing it. Zero keeps the one second
+// default of diffmatchpatch and diffs data of any size.
+//
+// Default: 0
+//noinspection GoUnusedExportedFunction
+func WithDiffTimeout(timeout time.Duration) Option {
+	return func(o OptionProcessor) error {
+		return o.WithDiffTimeout(timeout)
+	}
+}
+
+// WithDiffInvisibles makes invisible characters visible on the changed lines
+// of ClassicDiff, SideBySideDiff and InlineDiff: tabs as `→`, carriage returns
+// as `␍`, trailing spaces as `·`, other invisible characters as their code
+// point, e.g. `<U+00A0>`, and bytes that are not valid UTF-8 as their value,
+// e.g. `<0xFC>`.
+//
+// Default: false
+//noinspection GoUnusedExportedFunction
+func WithDiffInvisibles(show bool) Option {
+	return func(o OptionProcessor) error {
+		return o.WithDiffInvisibles(show)
+	}
+}
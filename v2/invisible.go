@@ -0,0 +1,189 @@
+package goldie
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Byte order marks of the encodings recognized when comparing text.
+const (
+	bomUTF8    = "\xef\xbb\xbf"
+	bomUTF16LE = "\xff\xfe"
+	bomUTF16BE = "\xfe\xff"
+)
+
+// visible makes the invisible characters of a changed line visible: tabs as
+// `→`, carriage returns as `␍`, trailing spaces as `·`, any other white
+// space, control or format character as its code point, e.g. `<U+00A0>`, and
+// bytes that are not valid UTF-8 as their value, e.g. `<0xFF>`. Spaces between
+// words are kept as they are.
+func visible(line string) string {
+	trailing := len(strings.TrimRightFunc(line, unicode.IsSpace))
+
+	var buf strings.Builder
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+
+		switch {
+		case r == '\t':
+			buf.WriteString("→")
+		case r == '\r':
+			buf.WriteString("␍")
+		case r == ' ' && i >= trailing:
+			buf.WriteString("·")
+		case r == ' ':
+			buf.WriteRune(r)
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&buf, "<0x%02X>", line[i])
+		case unicode.IsSpace(r) || unicode.IsControl(r) || unicode.In(r, unicode.Cf):
+			fmt.Fprintf(&buf, "<U+%04X>", r)
+		default:
+			buf.WriteString(line[i : i+size])
+		}
+
+		i += size
+	}
+
+	return buf.String()
+}
+
+// showInvisibles makes the invisible characters of the changed line visible,
+// if enabled.
+func (o diffOptions) showInvisibles(line string) string {
+	if !o.invisibles {
+		return line
+	}
+
+	return visible(line)
+}
+
+// visibleChangedLines makes the invisible characters visible on the removed
+// and added lines of a unified diff.
+func visibleChangedLines(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		// The first two lines are the file headers.
+		if i < 2 || !(strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+")) {
+			continue
+		}
+
+		text := strings.TrimSuffix(line, "\n")
+		lines[i] = text[:1] + visible(text[1:]) + line[len(text):]
+	}
+
+	return strings.Join(lines, "")
+}
+
+// textFormat describes the properties of a text that are easily overlooked
+// in a diff.
+type textFormat struct {
+	encoding        string
+	lineEndings     string
+	trailingNewline string
+}
+
+// parseText decodes the text to UTF-8 and normalizes its line endings to LF,
+// returning its format along with the normalized text without a trailing
+// newline.
+func parseText(data string) (textFormat, string) {
+	var format textFormat
+
+	switch {
+	case strings.HasPrefix(data, bomUTF8):
+		format.encoding = "UTF-8 with BOM"
+		data = data[len(bomUTF8):]
+	case strings.HasPrefix(data, bomUTF16LE) && len(data)%2 == 0:
+		format.encoding = "UTF-16LE"
+		data = decodeUTF16(data[len(bomUTF16LE):], binary.LittleEndian)
+	case strings.HasPrefix(data, bomUTF16BE) && len(data)%2 == 0:
+		format.encoding = "UTF-16BE"
+		data = decodeUTF16(data[len(bomUTF16BE):], binary.BigEndian)
+	case utf8.ValidString(data):
+		format.encoding = "UTF-8"
+	default:
+		format.encoding = "not UTF-8"
+	}
+
+	crlf := strings.Count(data, "\r\n")
+	lf := strings.Count(data, "\n") - crlf
+	switch {
+	case crlf > 0 && lf > 0:
+		format.lineEndings = "mixed"
+	case crlf > 0:
+		format.lineEndings = "CRLF"
+	case lf > 0:
+		format.lineEndings = "LF"
+	default:
+		format.lineEndings = "none"
+	}
+	data = strings.Replace(data, "\r\n", "\n", -1)
+
+	format.trailingNewline = "none"
+	if strings.HasSuffix(data, "\n") {
+		format.trailingNewline = "one"
+		data = strings.TrimSuffix(data, "\n")
+	}
+
+	return format, data
+}
+
+// decodeUTF16 decodes UTF-16 data in the byte order to UTF-8.
+func decodeUTF16(data string, order binary.ByteOrder) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, order.Uint16([]byte(data[i:i+2])))
+	}
+
+	return string(utf16.Decode(units))
+}
+
+// diffHeadline explains a mismatch that's only caused by the line endings,
+// the trailing newline or the encoding, which are hard to spot in a diff. It
+// returns an empty string if the contents differ, too.
+//
+//	Only the line endings and the trailing newline differ:
+//	  line endings: expected CRLF, got LF
+//	  trailing newline: expected one, got none
+func diffHeadline(actual, expected string) string {
+	expectedFormat, expectedText := parseText(expected)
+	actualFormat, actualText := parseText(actual)
+
+	if expectedText != actualText {
+		return ""
+	}
+
+	var aspects, details []string
+	add := func(aspect, expected, actual string) {
+		if expected != actual {
+			aspects = append(aspects, "the "+aspect)
+			details = append(details, fmt.Sprintf("  %s: expected %s, got %s\n", aspect, expected, actual))
+		}
+	}
+	add("encoding", expectedFormat.encoding, actualFormat.encoding)
+	add("line endings", expectedFormat.lineEndings, actualFormat.lineEndings)
+	add("trailing newline", expectedFormat.trailingNewline, actualFormat.trailingNewline)
+
+	if len(aspects) == 0 {
+		return ""
+	}
+
+	verb := "differ"
+	if len(aspects) == 1 && aspects[0] != "the line endings" {
+		verb = "differs"
+	}
+
+	return fmt.Sprintf("Only %s %s:\n%s", joinAnd(aspects), verb, strings.Join(details, ""))
+}
+
+// joinAnd joins the words with commas and a final `and`.
+func joinAnd(words []string) string {
+	if len(words) == 1 {
+		return words[0]
+	}
+
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
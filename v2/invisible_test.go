@@ -0,0 +1,108 @@
+package goldie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVisible(t *testing.T) {
+	tests := map[string]struct {
+		line     string
+		expected string
+	}{
+		"plain":                {line: "a b", expected: "a b"},
+		"tabs":                 {line: "\ta\tb", expected: "→a→b"},
+		"trailing spaces":      {line: "a b  ", expected: "a b··"},
+		"carriage return":      {line: "a \r", expected: "a·␍"},
+		"non-breaking space":   {line: "a\u00a0b", expected: "a<U+00A0>b"},
+		"zero width space":     {line: "a\u200bb", expected: "a<U+200B>b"},
+		"byte order mark":      {line: "\ufeffa", expected: "<U+FEFF>a"},
+		"control characters":   {line: "a\x00b", expected: "a<U+0000>b"},
+		"invalid UTF-8":        {line: "a\xffb", expected: "a<0xFF>b"},
+		"Latin-1":              {line: "gr\xfcn", expected: "gr<0xFC>n"},
+		"multi-byte unchanged": {line: "äö", expected: "äö"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, visible(test.line))
+		})
+	}
+}
+
+func TestDiffHeadline(t *testing.T) {
+	tests := map[string]struct {
+		actual   string
+		expected string
+		headline string
+	}{
+		"content differs": {
+			actual:   "a\nb\n",
+			expected: "a\nc\r\n",
+		},
+		"line endings": {
+			actual:   "a\nb\n",
+			expected: "a\r\nb\r\n",
+			headline: "Only the line endings differ:\n  line endings: expected CRLF, got LF\n",
+		},
+		"trailing newline": {
+			actual:   "a\nb",
+			expected: "a\nb\n",
+			headline: "Only the trailing newline differs:\n  trailing newline: expected one, got none\n",
+		},
+		"byte order mark": {
+			actual:   "\ufeffa\n",
+			expected: "a\n",
+			headline: "Only the encoding differs:\n  encoding: expected UTF-8, got UTF-8 with BOM\n",
+		},
+		"UTF-16": {
+			actual:   "\xff\xfea\x00\r\x00\n\x00",
+			expected: "a\n",
+			headline: "Only the encoding and the line endings differ:\n" +
+				"  encoding: expected UTF-8, got UTF-16LE\n" +
+				"  line endings: expected LF, got CRLF\n",
+		},
+		"mixed line endings and trailing newline": {
+			actual:   "a\r\nb\n",
+			expected: "a\nb",
+			headline: "Only the line endings and the trailing newline differ:\n" +
+				"  line endings: expected LF, got mixed\n" +
+				"  trailing newline: expected none, got one\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.headline, diffHeadline(test.actual, test.expected))
+		})
+	}
+}
+
+func TestDiffInvisibles(t *testing.T) {
+	opts := newDiffOptions()
+	assert.False(t, opts.invisibles)
+
+	// The headline does not depend on the option.
+	assert.Equal(t, "Only the line endings differ:\n  line endings: expected LF, got CRLF\n"+
+		"--- Expected\n+++ Actual\n@@ -1,3 +1,3 @@\n-a\n-b\n+a\r\n+b\r\n \n",
+		opts.diff(ClassicDiff, "a\r\nb\r\n", "a\nb\n"))
+
+	assert.Equal(t, "--- Expected\n+++ Actual\n@@ -1,2 +1,2 @@\n-a \n+a\n \n",
+		opts.diff(ClassicDiff, "a\n", "a \n"))
+
+	opts.invisibles = true
+	assert.Equal(t, "Only the line endings differ:\n  line endings: expected LF, got CRLF\n"+
+		"--- Expected\n+++ Actual\n@@ -1,3 +1,3 @@\n-a\n-b\n+a␍\n+b␍\n \n",
+		opts.diff(ClassicDiff, "a\r\nb\r\n", "a\nb\n"))
+
+	assert.Equal(t, "--- Expected\n+++ Actual\n@@ -1,3 +1,3 @@\n-a·\n-→b\n+a\n+    b\n \n",
+		opts.diff(ClassicDiff, "a\n    b\n", "a \n\tb\n"))
+
+	// Latin-1 text is compared as text, showing the invalid bytes.
+	assert.Equal(t, "--- Expected\n+++ Actual\n@@ -1,2 +1,2 @@\n-colour: gr<0xE4>n\n+colour: gr<0xFC>n\n \n",
+		opts.diff(ClassicDiff, "colour: gr\xfcn\n", "colour: gr\xe4n\n"))
+
+	g := New(t, WithDiffInvisibles(true))
+	assert.True(t, g.diffOptions.invisibles)
+}
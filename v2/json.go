@@ -0,0 +1,240 @@
+package goldie
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// decodeJson decodes a single JSON value, keeping numbers as json.Number so
+// that they can be compared without losing precision.
+func decodeJson(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+
+	return v, nil
+}
+
+// normalizeJson decodes the JSON data and encodes it again, pretty-printed
+// and with sorted object keys.
+func normalizeJson(data []byte) ([]byte, error) {
+	v, err := decodeJson(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeJson(v)
+}
+
+// encodeJson encodes a decoded JSON value pretty-printed and with sorted
+// object keys.
+func encodeJson(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// jsonEqual compares two decoded JSON values structurally. Objects are
+// compared regardless of key order and numbers are compared numerically.
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+
+	case json.Number:
+		b, ok := b.(json.Number)
+		return ok && jsonNumberEqual(a, b)
+
+	default:
+		return a == b
+	}
+}
+
+// jsonNumberEqual compares two JSON numbers numerically, so that e.g. 1,
+// 1.0 and 1e0 are equal.
+func jsonNumberEqual(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+
+	x, ok := new(big.Rat).SetString(string(a))
+	if !ok {
+		return false
+	}
+
+	y, ok := new(big.Rat).SetString(string(b))
+	if !ok {
+		return false
+	}
+
+	return x.Cmp(y) == 0
+}
+
+// jsonEqualFn is an EqualFn that compares JSON documents structurally. Data
+// that cannot be decoded is never equal.
+func jsonEqualFn(actual, expected []byte) bool {
+	a, err := decodeJson(actual)
+	if err != nil {
+		return false
+	}
+
+	e, err := decodeJson(expected)
+	if err != nil {
+		return false
+	}
+
+	return jsonEqual(a, e)
+}
+
+// jsonDiff describes the differences between two JSON documents as a list
+// of JSON Pointer paths, one difference per line. ok is false if either of
+// the documents is not valid JSON.
+func jsonDiff(actual, expected string) (diff string, ok bool) {
+	a, err := decodeJson([]byte(actual))
+	if err != nil {
+		return "", false
+	}
+
+	e, err := decodeJson([]byte(expected))
+	if err != nil {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	writeJsonDiff(&buf, "", a, e)
+
+	return buf.String(), true
+}
+
+// writeJsonDiff writes the differences between the actual and expected
+// values found at path.
+func writeJsonDiff(buf *bytes.Buffer, path string, actual, expected interface{}) {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(e)+len(a))
+		for key := range e {
+			keys = append(keys, key)
+		}
+		for key := range a {
+			if _, ok := e[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			p := path + "/" + jsonPointerEscaper.Replace(key)
+			ev, inExpected := e[key]
+			av, inActual := a[key]
+
+			switch {
+			case !inActual:
+				fmt.Fprintf(buf, "%s: missing, expected %s\n", jsonPointer(p), jsonText(ev))
+			case !inExpected:
+				fmt.Fprintf(buf, "%s: unexpected, got %s\n", jsonPointer(p), jsonText(av))
+			default:
+				writeJsonDiff(buf, p, av, ev)
+			}
+		}
+		return
+
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+
+		if len(a) != len(e) {
+			fmt.Fprintf(buf, "%s: expected %d elements, got %d\n", jsonPointer(path), len(e), len(a))
+		}
+
+		for i := 0; i < len(a) || i < len(e); i++ {
+			p := fmt.Sprintf("%s/%d", path, i)
+
+			switch {
+			case i >= len(a):
+				fmt.Fprintf(buf, "%s: missing, expected %s\n", jsonPointer(p), jsonText(e[i]))
+			case i >= len(e):
+				fmt.Fprintf(buf, "%s: unexpected, got %s\n", jsonPointer(p), jsonText(a[i]))
+			default:
+				writeJsonDiff(buf, p, a[i], e[i])
+			}
+		}
+		return
+	}
+
+	if !jsonEqual(actual, expected) {
+		fmt.Fprintf(buf, "%s: expected %s, got %s\n", jsonPointer(path), jsonText(expected), jsonText(actual))
+	}
+}
+
+// jsonPointerEscaper escapes reference tokens according to RFC 6901.
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonPointer formats the JSON Pointer path for display. The empty path
+// refers to the whole document.
+func jsonPointer(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+
+	return path
+}
+
+// jsonText formats a decoded JSON value as compact JSON.
+func jsonText(v interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
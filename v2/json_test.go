@@ -0,0 +1,155 @@
+package goldie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeJson(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		expected string
+		err      bool
+	}{
+		"object": {
+			data:     `{"b":1,"a":{"d":[1,2.50],"c":"<x>"}}`,
+			expected: "{\n  \"a\": {\n    \"c\": \"<x>\",\n    \"d\": [\n      1,\n      2.50\n    ]\n  },\n  \"b\": 1\n}",
+		},
+		"scalar": {
+			data:     ` "abc" `,
+			expected: `"abc"`,
+		},
+		"invalid": {
+			data: `{"a":`,
+			err:  true,
+		},
+		"trailing data": {
+			data: `{} {}`,
+			err:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := normalizeJson([]byte(test.data))
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, string(actual))
+		})
+	}
+}
+
+func TestJsonEqualFn(t *testing.T) {
+	tests := map[string]struct {
+		actual   string
+		expected string
+		equal    bool
+	}{
+		"key order": {
+			actual:   `{"a":1,"b":2}`,
+			expected: `{"b":2,"a":1}`,
+			equal:    true,
+		},
+		"whitespace": {
+			actual:   `{"a":[1,2]}`,
+			expected: "{\n  \"a\": [ 1, 2 ]\n}",
+			equal:    true,
+		},
+		"numbers": {
+			actual:   `[1, 100, 0.5]`,
+			expected: `[1.0, 1e2, 5E-1]`,
+			equal:    true,
+		},
+		"large numbers": {
+			actual:   `12345678901234567890`,
+			expected: `12345678901234567891`,
+			equal:    false,
+		},
+		"array order": {
+			actual:   `[1, 2]`,
+			expected: `[2, 1]`,
+			equal:    false,
+		},
+		"string and number": {
+			actual:   `{"a":"1"}`,
+			expected: `{"a":1}`,
+			equal:    false,
+		},
+		"missing key": {
+			actual:   `{"a":1}`,
+			expected: `{"a":1,"b":null}`,
+			equal:    false,
+		},
+		"invalid": {
+			actual:   `{`,
+			expected: `{`,
+			equal:    false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.equal, jsonEqualFn([]byte(test.actual), []byte(test.expected)))
+		})
+	}
+}
+
+func TestJsonDiff(t *testing.T) {
+	tests := map[string]struct {
+		actual   string
+		expected string
+		diff     string
+	}{
+		"equal": {
+			actual:   `{"a":[1,2],"b":{"c":true}}`,
+			expected: `{"b":{"c":true},"a":[1,2.0]}`,
+			diff:     "",
+		},
+		"changed values": {
+			actual:   `{"items":[{"price":10},{"price":12}]}`,
+			expected: `{"items":[{"price":10},{"price":10}]}`,
+			diff:     "/items/1/price: expected 10, got 12\n",
+		},
+		"added and removed keys": {
+			actual:   `{"meta":{"new":5}}`,
+			expected: `{"meta":{"requestId":"abc"}}`,
+			diff:     "/meta/new: unexpected, got 5\n/meta/requestId: missing, expected \"abc\"\n",
+		},
+		"array length": {
+			actual:   `{"tags":["a","b","c"]}`,
+			expected: `{"tags":["a","x"]}`,
+			diff:     "/tags: expected 2 elements, got 3\n/tags/1: expected \"x\", got \"b\"\n/tags/2: unexpected, got \"c\"\n",
+		},
+		"type change": {
+			actual:   `{"a":[1]}`,
+			expected: `{"a":{"b":1}}`,
+			diff:     "/a: expected {\"b\":1}, got [1]\n",
+		},
+		"escaped keys": {
+			actual:   `{"a/b":{"c~d":1}}`,
+			expected: `{"a/b":{"c~d":2}}`,
+			diff:     "/a~1b/c~0d: expected 2, got 1\n",
+		},
+		"root": {
+			actual:   `1`,
+			expected: `"1"`,
+			diff:     "(root): expected \"1\", got 1\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			diff, ok := jsonDiff(test.actual, test.expected)
+			assert.True(t, ok)
+			assert.Equal(t, test.diff, diff)
+		})
+	}
+
+	_, ok := jsonDiff("{", "{}")
+	assert.False(t, ok)
+}
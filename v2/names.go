@@ -0,0 +1,111 @@
+package goldie
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// NamePolicy determines how fixture names, and the test names used for the
+// fixture directories, that are not portable file names are handled. A
+// portable name only consists of letters, digits, `.`, `-` and `_`, does not
+// start or end with a dot and is not reserved on Windows. A `/` in a fixture
+// name separates directories, just like in test names.
+//
+// Regardless of the policy, golden files resolving to a path outside of the
+// fixture directory are always rejected with a FixturePathEscapeError.
+type NamePolicy int
+
+const (
+	// NameAsIs uses the names as they are.
+	NameAsIs NamePolicy = iota
+
+	// NameReject rejects names that are not portable with an
+	// InvalidFixtureNameError.
+	NameReject
+
+	// NameEscape replaces the characters of names that are not portable with
+	// `_`, e.g. `a:b` becomes `a_b`. Different names may thus share a golden
+	// file.
+	NameEscape
+
+	// NameHash escapes names that are not portable, like NameEscape, and
+	// appends a short hash of the original name, e.g. `a_b-6783a31e`, so
+	// that different names never share a golden file.
+	NameHash
+)
+
+// apply applies the policy to a single part of a golden file path.
+func (p NamePolicy) apply(part string) (string, bool) {
+	safe := sanitizeName(part)
+	if p == NameAsIs || safe == part {
+		return part, true
+	}
+
+	switch p {
+	case NameEscape:
+		return safe, true
+
+	case NameHash:
+		sum := sha256.Sum256([]byte(part))
+		return safe + "-" + hex.EncodeToString(sum[:4]), true
+	}
+
+	return part, false
+}
+
+// goldenFileName returns the file name of the golden file fixture, applying
+// the name policy. An error is returned if a name is rejected by the policy,
+// or if the golden file is outside of the fixture directory.
+func (g *Goldie) goldenFileName(t TB, name string) (string, error) {
+	var parts []string
+
+	if _, ok := t.(*testing.F); ok || g.useTestNameForDir {
+		parts = append(parts, strings.Split(t.Name(), "/")[0])
+	}
+
+	if g.useSubTestNameForDir {
+		n := strings.Split(t.Name(), "/")
+		if len(n) > 1 {
+			parts = append(parts, n[1:]...)
+		}
+	}
+
+	parts = append(parts, strings.Split(name, "/")...)
+
+	return g.fixturePath(t, parts, g.fileNameSuffix)
+}
+
+// fixturePath joins the parts of a name, applying the name policy, into the
+// path of a file with the suffix in the fixture directory. An error is
+// returned if a part is rejected by the policy, or if the path is outside of
+// the fixture directory. The path is returned in any case.
+func (g *Goldie) fixturePath(t TB, parts []string, suffix string) (string, error) {
+	var err error
+	for i, part := range parts {
+		var ok bool
+		if parts[i], ok = g.namePolicy.apply(part); !ok && err == nil {
+			err = newErrInvalidFixtureName(part, t.Name())
+		}
+	}
+
+	path := filepath.Join(g.fixtureDir, fmt.Sprintf("%s%s", filepath.Join(parts...), suffix))
+	if err != nil {
+		return path, err
+	}
+
+	if !isLocalPath(g.fixtureDir, path) {
+		return path, newErrFixturePathEscape(path, g.fixtureDir, t.Name())
+	}
+
+	return path, nil
+}
+
+// isLocalPath reports whether the path is within the directory.
+func isLocalPath(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,109 @@
+package goldie
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoldenFileNamePolicy(t *testing.T) {
+	tests := map[string]struct {
+		policy   NamePolicy
+		testName string
+		name     string
+		expected string
+		err      error
+	}{
+		"as is": {
+			policy:   NameAsIs,
+			testName: "TestExample/a:b",
+			name:     "sub/x*y",
+			expected: "testdata/TestExample/a:b/sub/x*y.golden",
+		},
+		"reject": {
+			policy:   NameReject,
+			testName: "TestExample/a:b",
+			name:     "example",
+			expected: "testdata/TestExample/a:b/example.golden",
+			err:      ErrInvalidFixtureName,
+		},
+		"reject portable": {
+			policy:   NameReject,
+			testName: "TestExample/a_b",
+			name:     "sub/example",
+			expected: "testdata/TestExample/a_b/sub/example.golden",
+		},
+		"escape": {
+			policy:   NameEscape,
+			testName: "TestExample/a:b",
+			name:     "sub/x*y",
+			expected: "testdata/TestExample/a_b/sub/x_y.golden",
+		},
+		"hash": {
+			policy:   NameHash,
+			testName: "TestExample/a_b",
+			name:     "x*y",
+			expected: "testdata/TestExample/a_b/x_y-7a6ba644.golden",
+		},
+		"escape traversal": {
+			policy:   NameEscape,
+			testName: "TestExample/..",
+			name:     "../example",
+			expected: "testdata/TestExample/_/_/example.golden",
+		},
+		"as is traversal": {
+			policy:   NameAsIs,
+			testName: "TestExample/..",
+			name:     "../example",
+			expected: "example.golden",
+			err:      ErrFixturePathEscape,
+		},
+		"reject traversal": {
+			policy:   NameReject,
+			testName: "TestExample",
+			name:     "../example",
+			expected: "testdata/example.golden",
+			err:      ErrInvalidFixtureName,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ft := &fakeTB{name: test.testName}
+			g := New(ft, WithTestNameForDir(true), WithSubTestNameForDir(true), WithNamePolicy(test.policy))
+
+			goldenFile, err := g.goldenFileName(ft, test.name)
+			assert.Equal(t, test.expected, goldenFile)
+			assert.Equal(t, test.expected, g.GoldenFileName(ft, test.name))
+			if test.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, test.err), err)
+			}
+		})
+	}
+}
+
+func TestAssertPathEscape(t *testing.T) {
+	g := New(t, WithFixtureDir(t.TempDir()))
+
+	err := g.Update(t, "../../outside", []byte("abc"))
+	var e *FixturePathEscapeError
+	require.True(t, errors.As(err, &e), err)
+	assert.Equal(t, g.fixtureDir, e.FixtureDir)
+
+	ft := &fakeTB{name: t.Name()}
+	g.Assert(ft, "../outside", []byte("abc"))
+	assert.True(t, ft.failed)
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "is outside of the fixture folder")
+}
+
+func TestWithNamePolicy(t *testing.T) {
+	g := &Goldie{}
+	assert.NoError(t, g.WithNamePolicy(NameHash))
+	assert.Equal(t, NameHash, g.namePolicy)
+	assert.Error(t, g.WithNamePolicy(NamePolicy(42)))
+}
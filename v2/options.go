@@ -1,6 +1,13 @@
 package goldie
 
-import "os"
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
 
 // WithFixtureDir sets the fixture directory.
 //
@@ -85,3 +92,186 @@ func (g *Goldie) WithSubTestNameForDir(use bool) error {
 	g.useSubTestNameForDir = use
 	return nil
 }
+
+// WithIgnorePaths excludes fields from the comparison of AssertJson,
+// AssertJsonBytes and AssertXml. Ignored fields are replaced with a stable
+// placeholder in the stored golden file.
+//
+// Paths starting with `$` select JSON values, using a subset of JSONPath
+// (`$.meta.requestId`, `$.items[*].createdAt`, `$['a.b'][0]`). Paths
+// starting with `/` select XML elements or attributes, using a subset of
+// XPath (`/response/meta/requestId`, `//createdAt`, `//item/@id`).
+func (g *Goldie) WithIgnorePaths(paths ...string) error {
+	for _, path := range paths {
+		switch {
+		case strings.HasPrefix(path, "$"):
+			steps, err := parseJsonPath(path)
+			if err != nil {
+				return err
+			}
+			g.ignoreJsonPaths = append(g.ignoreJsonPaths, steps)
+
+		case strings.HasPrefix(path, "/"):
+			p, err := parseXmlPath(path)
+			if err != nil {
+				return err
+			}
+			g.ignoreXmlPaths = append(g.ignoreXmlPaths, p)
+
+		default:
+			return fmt.Errorf("invalid ignore path %q: must start with '$' or '/'", path)
+		}
+	}
+
+	return nil
+}
+
+// WithScrubber adds scrubbers that rewrite volatile content in the actual
+// data into stable tokens, before it's compared with or written to the
+// golden file. Scrubbers are applied in the order they are added.
+func (g *Goldie) WithScrubber(scrubbers ...Scrubber) error {
+	for _, s := range scrubbers {
+		if s.Name == "" || s.Pattern == nil {
+			return fmt.Errorf("scrubber must have a name and a pattern")
+		}
+	}
+
+	g.scrubbers = append(g.scrubbers, scrubbers...)
+	return nil
+}
+
+// WithReceivedFiles enables the approval workflow. Instead of failing only,
+// missing or mismatching golden files will have the actual data written to a
+// `.received` file next to the golden file, which can be promoted with
+// Accept or AcceptAll. Stale received files are removed when the golden file
+// matches.
+//
+// Default value is false.
+func (g *Goldie) WithReceivedFiles(use bool) error {
+	g.useReceivedFiles = use
+	return nil
+}
+
+// WithNamePolicy sets how fixture names that are not portable file names are
+// handled.
+func (g *Goldie) WithNamePolicy(policy NamePolicy) error {
+	switch policy {
+	case NameAsIs, NameReject, NameEscape, NameHash:
+	default:
+		return fmt.Errorf("invalid name policy: %d", policy)
+	}
+
+	g.namePolicy = policy
+	return nil
+}
+
+// WithVariants makes the golden files specific to the given variants of the
+// environment, e.g. the operating system. The most specific existing variant
+// of a golden file is used, falling back to the generic golden file.
+func (g *Goldie) WithVariants(variants ...Variant) error {
+	for _, v := range variants {
+		switch v {
+		case GOOS, GOARCH, GoVersionMinor:
+		default:
+			return fmt.Errorf("invalid variant: %d", v)
+		}
+	}
+
+	g.variants = append(g.variants, variants...)
+	return nil
+}
+
+// WithFS sets the file system the golden files are read from. To update golden
+// files, the file system must implement WritableFS.
+func (g *Goldie) WithFS(fsys fs.FS) error {
+	if fsys == nil {
+		return fmt.Errorf("file system must not be nil")
+	}
+
+	g.fsys = fsys
+	return nil
+}
+
+// WithSnapshots sets whether, and how, fixtures are stored in snapshot files
+// holding many fixtures each.
+func (g *Goldie) WithSnapshots(mode SnapshotMode) error {
+	switch mode {
+	case SnapshotOff, SnapshotPerTest, SnapshotPerFile:
+	default:
+		return fmt.Errorf("invalid snapshot mode: %d", mode)
+	}
+
+	g.snapshotMode = mode
+	return nil
+}
+
+// WithSnapshotFile stores the fixtures of all tests in the snapshot file with
+// the given name, without the suffix, in the fixture directory. Names leaving
+// the fixture directory are rejected.
+func (g *Goldie) WithSnapshotFile(name string) error {
+	if filepath.Clean(name) == "." || !isLocalPath(".", filepath.FromSlash(name)) {
+		return fmt.Errorf("invalid snapshot file name %q: must be a path within the fixture directory", name)
+	}
+
+	g.snapshotMode = SnapshotPerFile
+	g.snapshotFileName = name
+	return nil
+}
+
+// WithDiffWidth sets the width of the diff output used if neither the COLUMNS
+// environment variable nor the width of the terminal is available.
+func (g *Goldie) WithDiffWidth(width int) error {
+	if width <= 0 {
+		return fmt.Errorf("invalid diff width: %d", width)
+	}
+
+	g.diffOptions.width = width
+	return nil
+}
+
+// WithDiffColor enables ANSI colors in the diff engines supporting both
+// colored and plain output.
+func (g *Goldie) WithDiffColor(color bool) error {
+	g.diffOptions.color = color
+	return nil
+}
+
+// WithDiffContext sets the number of unchanged lines shown around the changed
+// lines.
+func (g *Goldie) WithDiffContext(lines int) error {
+	if lines < 0 {
+		return fmt.Errorf("invalid number of diff context lines: %d", lines)
+	}
+
+	g.diffOptions.context = lines
+	return nil
+}
+
+// WithDiffLimits sets the maximal number of lines and bytes of the diff
+// output.
+func (g *Goldie) WithDiffLimits(maxLines, maxSize int) error {
+	if maxLines < 0 || maxSize < 0 {
+		return fmt.Errorf("invalid diff limits: %d lines, %d bytes", maxLines, maxSize)
+	}
+
+	g.diffOptions.maxLines = maxLines
+	g.diffOptions.maxSize = maxSize
+	return nil
+}
+
+// WithDiffTimeout sets the time budget of the diff algorithm, which also
+// bounds the size of the data diffed by the line based engines.
+func (g *Goldie) WithDiffTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("invalid diff timeout: %s", timeout)
+	}
+
+	g.diffOptions.timeout = timeout
+	return nil
+}
+
+// WithDiffInvisibles makes invisible characters visible on changed lines.
+func (g *Goldie) WithDiffInvisibles(show bool) error {
+	g.diffOptions.invisibles = show
+	return nil
+}
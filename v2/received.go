@@ -0,0 +1,115 @@
+package goldie
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// receivedSuffix is the suffix of received files, replacing the golden file
+// name suffix.
+const receivedSuffix = ".received"
+
+// ReceivedFileName returns the file name of the received file, which is
+// written next to the golden file when running in approval mode. It's the
+// golden file name with the name suffix replaced by `.received`.
+func (g *Goldie) ReceivedFileName(t TB, name string) string {
+	return g.receivedFile(g.GoldenFileName(t, name))
+}
+
+// Accept promotes the received file of the fixture to be the golden file,
+// removing the received file.
+func (g *Goldie) Accept(t TB, name string) error {
+	goldenFile, err := g.goldenFileName(t, name)
+	if err != nil {
+		return err
+	}
+
+	goldenFile = g.resolveVariant(goldenFile)
+	return g.promote(g.receivedFile(goldenFile), goldenFile)
+}
+
+// AcceptAll promotes all received files found in dir, or any of its sub
+// directories, to golden files with the given name suffix. It returns the
+// names of the golden files written.
+func AcceptAll(dir string, suffix string) ([]string, error) {
+	g := Goldie{fileNameSuffix: suffix, filePerms: defaultFilePerms, dirPerms: defaultDirPerms, fsys: osFS{}}
+
+	var accepted []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(path, receivedSuffix) {
+			return nil
+		}
+
+		goldenFile := strings.TrimSuffix(path, receivedSuffix) + suffix
+		if err := g.promote(path, goldenFile); err != nil {
+			return err
+		}
+
+		accepted = append(accepted, goldenFile)
+		return nil
+	})
+
+	return accepted, err
+}
+
+// receivedFile returns the name of the received file belonging to the
+// golden file.
+func (g *Goldie) receivedFile(goldenFile string) string {
+	return strings.TrimSuffix(goldenFile, g.fileNameSuffix) + receivedSuffix
+}
+
+// promote renames the received file to the golden file.
+func (g *Goldie) promote(receivedFile, goldenFile string) error {
+	wfs, err := g.writableFS()
+	if err != nil {
+		return err
+	}
+
+	if err := wfs.MkdirAll(filepath.ToSlash(filepath.Dir(goldenFile)), g.dirPerms); err != nil {
+		return err
+	}
+
+	return wfs.Rename(filepath.ToSlash(receivedFile), filepath.ToSlash(goldenFile))
+}
+
+// updateReceived writes the received file for missing or mismatching golden
+// files, and removes stale received files otherwise.
+func (g *Goldie) updateReceived(result *Result) error {
+	receivedFile := g.receivedFile(result.GoldenFile)
+
+	switch result.Status {
+	case StatusMissing, StatusMismatch:
+		wfs, err := g.writableFS()
+		if err != nil {
+			return err
+		}
+
+		if err := wfs.MkdirAll(filepath.ToSlash(filepath.Dir(receivedFile)), g.dirPerms); err != nil {
+			return err
+		}
+
+		if err := wfs.WriteFile(filepath.ToSlash(receivedFile), result.Actual, g.filePerms); err != nil {
+			return err
+		}
+
+		result.ReceivedFile = receivedFile
+		return nil
+
+	default:
+		if _, err := g.stat(receivedFile); err != nil {
+			return nil
+		}
+
+		wfs, err := g.writableFS()
+		if err != nil {
+			return err
+		}
+
+		return wfs.RemoveAll(filepath.ToSlash(receivedFile))
+	}
+}
@@ -0,0 +1,110 @@
+package goldie
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceivedFileName(t *testing.T) {
+	tests := map[string]struct {
+		options  []Option
+		expected string
+	}{
+		"using defaults": {
+			expected: "testdata/example.received",
+		},
+		"with custom suffix": {
+			options:  []Option{WithNameSuffix(".golden.json")},
+			expected: "testdata/example.received",
+		},
+		"without suffix": {
+			options:  []Option{WithNameSuffix("")},
+			expected: "testdata/example.received",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := New(t, test.options...)
+			assert.Equal(t, test.expected, g.ReceivedFileName(t, "example"))
+		})
+	}
+}
+
+func TestReceivedFiles(t *testing.T) {
+	g := New(t, WithFixtureDir(t.TempDir()), WithReceivedFiles(true))
+	goldenFile := g.GoldenFileName(t, "example")
+	receivedFile := g.ReceivedFileName(t, "example")
+
+	// A missing golden file writes a received file.
+	result, err := g.Check(t, "example", []byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusMissing, result.Status)
+	assert.Equal(t, receivedFile, result.ReceivedFile)
+	assertFileContent(t, receivedFile, "abc")
+
+	// Accepting it promotes it to the golden file.
+	err = g.Accept(t, "example")
+	require.NoError(t, err)
+	assertFileContent(t, goldenFile, "abc")
+	assertNoFile(t, receivedFile)
+
+	// A mismatch writes a received file and leaves the golden file alone.
+	result, err = g.Check(t, "example", []byte("abcd"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusMismatch, result.Status)
+	assertFileContent(t, receivedFile, "abcd")
+	assertFileContent(t, goldenFile, "abc")
+
+	// A match removes the stale received file.
+	result, err = g.Check(t, "example", []byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusMatch, result.Status)
+	assert.Empty(t, result.ReceivedFile)
+	assertNoFile(t, receivedFile)
+}
+
+func TestAcceptAll(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.received":          "a",
+		"sub/b.received":      "b",
+		"sub/c.golden":        "c",
+		"sub/deeper/d.golden": "d",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	accepted, err := AcceptAll(dir, ".golden")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "a.golden"),
+		filepath.Join(dir, "sub/b.golden"),
+	}, accepted)
+
+	assertFileContent(t, filepath.Join(dir, "a.golden"), "a")
+	assertFileContent(t, filepath.Join(dir, "sub/b.golden"), "b")
+	assertNoFile(t, filepath.Join(dir, "a.received"))
+}
+
+func assertFileContent(t *testing.T, path string, expected string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, string(data))
+	}
+}
+
+func assertNoFile(t *testing.T, path string) {
+	t.Helper()
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "expected %s to not exist", path)
+}
@@ -0,0 +1,78 @@
+package goldie
+
+// Status describes the outcome of comparing actual data with a golden file.
+type Status int
+
+const (
+	// StatusMatch is used when the actual data matches the golden file.
+	StatusMatch Status = iota
+
+	// StatusMismatch is used when the actual data differs from the golden
+	// file.
+	StatusMismatch
+
+	// StatusMissing is used when the golden file does not exist.
+	StatusMissing
+
+	// StatusUpdated is used when the golden file was written with the actual
+	// data, i.e. when running with the `-update` flag, and matches it. An
+	// updated golden file that still does not match is a StatusMismatch.
+	StatusUpdated
+)
+
+// String returns a human readable representation of the status.
+func (s Status) String() string {
+	switch s {
+	case StatusMatch:
+		return "match"
+	case StatusMismatch:
+		return "mismatch"
+	case StatusMissing:
+		return "missing"
+	case StatusUpdated:
+		return "updated"
+	default:
+		return "unknown"
+	}
+}
+
+// Result holds the outcome of a Check.
+type Result struct {
+	// GoldenFile is the path of the golden file that was compared.
+	GoldenFile string
+
+	// TestName is the name of the test asserting the golden file.
+	TestName string
+
+	// Expected is the data read from the golden file. It is nil if the
+	// golden file is missing.
+	Expected []byte
+
+	// Actual is the data that was compared with the golden file.
+	Actual []byte
+
+	// Diff is the rendered difference between expected and actual data. It
+	// is only set when the status is StatusMismatch.
+	Diff string
+
+	// Status is the outcome of the comparison.
+	Status Status
+
+	// ReceivedFile is the path of the received file written with the actual
+	// data when using received files. It is only set when the status is
+	// StatusMismatch or StatusMissing.
+	ReceivedFile string
+}
+
+// err converts the result into the error that Assert reports, or nil if the
+// actual data matches the golden file.
+func (r *Result) err() error {
+	switch r.Status {
+	case StatusMissing:
+		return newErrFixtureNotFound(r.GoldenFile, r.TestName)
+	case StatusMismatch:
+		return newErrFixtureMismatch(r)
+	default:
+		return nil
+	}
+}
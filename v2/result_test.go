@@ -0,0 +1,31 @@
+package goldie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusString(t *testing.T) {
+	tests := map[Status]string{
+		StatusMatch:    "match",
+		StatusMismatch: "mismatch",
+		StatusMissing:  "missing",
+		StatusUpdated:  "updated",
+		Status(42):     "unknown",
+	}
+
+	for status, expected := range tests {
+		assert.Equal(t, expected, status.String())
+	}
+}
+
+func TestResultErr(t *testing.T) {
+	assert.Nil(t, (&Result{Status: StatusMatch}).err())
+	assert.Nil(t, (&Result{Status: StatusUpdated}).err())
+	assert.IsType(t, &FixtureNotFoundError{}, (&Result{Status: StatusMissing}).err())
+
+	err := (&Result{Status: StatusMismatch, Diff: "some diff"}).err()
+	assert.IsType(t, &FixtureMismatchError{}, err)
+	assert.Contains(t, err.Error(), "some diff")
+}
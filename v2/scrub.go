@@ -0,0 +1,130 @@
+package goldie
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Scrubber rewrites volatile content, like generated IDs or timestamps, into
+// stable tokens before the actual data is compared with or written to the
+// golden file.
+//
+// Every match of Pattern is replaced with a numbered token like `<UUID-1>`.
+// Within one fixture, equal matches are replaced with the same token. If
+// Pattern has capture groups, only the first group is replaced.
+type Scrubber struct {
+	// Name is used in the tokens, e.g. `UUID` produces `<UUID-1>`.
+	Name string
+
+	// Pattern matches the content to scrub.
+	Pattern *regexp.Regexp
+}
+
+var (
+	// UUIDScrubber replaces UUIDs with `<UUID-n>` tokens.
+	UUIDScrubber = Scrubber{
+		Name:    "UUID",
+		Pattern: regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`),
+	}
+
+	// TimestampScrubber replaces RFC3339 timestamps with `<TIMESTAMP-n>`
+	// tokens.
+	TimestampScrubber = Scrubber{
+		Name:    "TIMESTAMP",
+		Pattern: regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[Tt ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:[Zz]|[+-]\d{2}:\d{2})`),
+	}
+
+	// TempDirScrubber replaces paths within the temporary directory, e.g.
+	// the ones created by t.TempDir(), with `<TEMPDIR-n>` tokens.
+	TempDirScrubber = Scrubber{
+		Name:    "TEMPDIR",
+		Pattern: regexp.MustCompile(regexp.QuoteMeta(filepath.Clean(os.TempDir())) + `(?:[/\\][^\s"'<>]*)?`),
+	}
+
+	// PortScrubber replaces the ports of local addresses, e.g. the ones of
+	// httptest servers, with `<PORT-n>` tokens.
+	PortScrubber = Scrubber{
+		Name:    "PORT",
+		Pattern: regexp.MustCompile(`(?:localhost|127\.0\.0\.1|\[::1?\]|0\.0\.0\.0):(\d{1,5})\b`),
+	}
+
+	// HexHashScrubber replaces lower case hex encoded MD5, SHA-1 and SHA-256
+	// hashes with `<HASH-n>` tokens.
+	HexHashScrubber = Scrubber{
+		Name:    "HASH",
+		Pattern: regexp.MustCompile(`\b(?:[0-9a-f]{64}|[0-9a-f]{40}|[0-9a-f]{32})\b`),
+	}
+
+	// DefaultScrubbers holds all built-in scrubbers, in an order where
+	// paths are scrubbed before the IDs they may contain.
+	DefaultScrubbers = []Scrubber{
+		TempDirScrubber,
+		UUIDScrubber,
+		TimestampScrubber,
+		PortScrubber,
+		HexHashScrubber,
+	}
+)
+
+// scrub applies all scrubbers to the data, numbering the tokens per
+// scrubber name. If escape is set, it's applied to the tokens before they
+// are inserted, e.g. to keep XML documents well-formed.
+func (g *Goldie) scrub(data []byte, escape func(string) string) []byte {
+	if len(g.scrubbers) == 0 {
+		return data
+	}
+
+	tokens := map[string]map[string]string{}
+	for _, s := range g.scrubbers {
+		if tokens[s.Name] == nil {
+			tokens[s.Name] = map[string]string{}
+		}
+		data = s.scrub(data, tokens[s.Name], escape)
+	}
+
+	return data
+}
+
+// scrub replaces the matches in data with tokens, reusing the tokens of
+// matches seen before.
+func (s Scrubber) scrub(data []byte, tokens map[string]string, escape func(string) string) []byte {
+	group := 0
+	if s.Pattern.NumSubexp() > 0 {
+		group = 1
+	}
+
+	var (
+		buf  bytes.Buffer
+		last int
+	)
+	for _, m := range s.Pattern.FindAllSubmatchIndex(data, -1) {
+		start, end := m[2*group], m[2*group+1]
+		if start < 0 {
+			continue
+		}
+
+		match := string(data[start:end])
+		token, ok := tokens[match]
+		if !ok {
+			token = fmt.Sprintf("<%s-%d>", s.Name, len(tokens)+1)
+			if escape != nil {
+				token = escape(token)
+			}
+			tokens[match] = token
+		}
+
+		buf.Write(data[last:start])
+		buf.WriteString(token)
+		last = end
+	}
+
+	if last == 0 {
+		return data
+	}
+
+	buf.Write(data[last:])
+	return buf.Bytes()
+}
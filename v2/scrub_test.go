@@ -0,0 +1,97 @@
+package goldie
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrub(t *testing.T) {
+	tests := map[string]struct {
+		scrubbers []Scrubber
+		data      string
+		expected  string
+	}{
+		"uuids": {
+			scrubbers: []Scrubber{UUIDScrubber},
+			data:      "a=0F8FAD5B-D9CB-469F-A165-70867728950E b=7c9e6679-7425-40de-944b-e07fc1f90ae7 c=0f8fad5b-d9cb-469f-a165-70867728950e d=0F8FAD5B-D9CB-469F-A165-70867728950E",
+			expected:  "a=<UUID-1> b=<UUID-2> c=<UUID-3> d=<UUID-1>",
+		},
+		"timestamps": {
+			scrubbers: []Scrubber{TimestampScrubber},
+			data:      `{"created":"2024-01-02T03:04:05Z","updated":"2024-01-02T03:04:05.123+02:00"}`,
+			expected:  `{"created":"<TIMESTAMP-1>","updated":"<TIMESTAMP-2>"}`,
+		},
+		"temp dirs": {
+			scrubbers: []Scrubber{TempDirScrubber},
+			data:      "wrote " + filepath.Join(os.TempDir(), "TestScrub123", "001", "out.txt") + " and " + os.TempDir(),
+			expected:  "wrote <TEMPDIR-1> and <TEMPDIR-2>",
+		},
+		"ports": {
+			scrubbers: []Scrubber{PortScrubber},
+			data:      "http://127.0.0.1:54321/path localhost:8080 127.0.0.1:54321",
+			expected:  "http://127.0.0.1:<PORT-1>/path localhost:<PORT-2> 127.0.0.1:<PORT-1>",
+		},
+		"hashes": {
+			scrubbers: []Scrubber{HexHashScrubber},
+			data:      "md5 d41d8cd98f00b204e9800998ecf8427e sha1 da39a3ee5e6b4b0d3255bfef95601890afd80709 short abcdef12",
+			expected:  "md5 <HASH-1> sha1 <HASH-2> short abcdef12",
+		},
+		"custom": {
+			scrubbers: []Scrubber{{Name: "ID", Pattern: regexp.MustCompile(`id=(\d+)`)}},
+			data:      "id=12 id=13 id=12",
+			expected:  "id=<ID-1> id=<ID-2> id=<ID-1>",
+		},
+		"no matches": {
+			scrubbers: DefaultScrubbers,
+			data:      "nothing to see here",
+			expected:  "nothing to see here",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := New(t, WithScrubber(test.scrubbers...))
+			assert.Equal(t, test.expected, string(g.scrub([]byte(test.data), nil)))
+		})
+	}
+}
+
+func TestScrubEscaped(t *testing.T) {
+	g := New(t, WithFixtureDir(t.TempDir()), WithScrubber(UUIDScrubber))
+	actual := g.scrub([]byte("<id>7c9e6679-7425-40de-944b-e07fc1f90ae7</id>"), xmlEscape)
+	assert.Equal(t, "<id>&lt;UUID-1&gt;</id>", string(actual))
+}
+
+func TestWithScrubberValidation(t *testing.T) {
+	g := &Goldie{}
+	assert.Error(t, g.WithScrubber(Scrubber{Name: "ID"}))
+	assert.Error(t, g.WithScrubber(Scrubber{Pattern: regexp.MustCompile(`\d+`)}))
+}
+
+func TestAssertWithScrubber(t *testing.T) {
+	savedUpdateState := *update
+	t.Cleanup(func() {
+		*update = savedUpdateState
+	})
+
+	g := New(t, WithFixtureDir(t.TempDir()), WithScrubber(UUIDScrubber))
+
+	*update = true
+	g.Assert(t, "example", []byte("id: 7c9e6679-7425-40de-944b-e07fc1f90ae7"))
+	*update = savedUpdateState
+
+	data, err := os.ReadFile(g.GoldenFileName(t, "example"))
+	assert.Nil(t, err)
+	assert.Equal(t, "id: <UUID-1>", string(data))
+
+	ft := &fakeTB{name: t.Name()}
+	g.Assert(ft, "example", []byte("id: 0f8fad5b-d9cb-469f-a165-70867728950e"))
+	assert.Empty(t, ft.errors)
+
+	err = os.RemoveAll(g.fixtureDir)
+	assert.Nil(t, err)
+}
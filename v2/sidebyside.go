@@ -0,0 +1,209 @@
+package goldie
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// minSideBySideColumn is the minimal width of each column of the side by
+	// side diff, no matter how narrow the terminal is.
+	minSideBySideColumn = 20
+
+	// sideBySideTabWidth is the number of spaces tabs are expanded to, to
+	// keep the columns aligned.
+	sideBySideTabWidth = 4
+)
+
+// The gutters between the columns of the side by side diff, marking equal
+// lines, changed lines, lines only expected and lines only in the actual
+// data, like the `sdiff` tool does.
+const (
+	sideBySideEqual   = "   "
+	sideBySideChanged = " | "
+	sideBySideDeleted = " < "
+	sideBySideAdded   = " > "
+)
+
+// sideBySideLine is a line of either column of the side by side diff.
+type sideBySideLine struct {
+	// num is the line number, or 0 for no line.
+	num int
+
+	runes []rune
+
+	// changed reports for every rune whether it's highlighted.
+	changed []bool
+}
+
+// sideBySideWriter renders the rows of a side by side diff.
+type sideBySideWriter struct {
+	buf      strings.Builder
+	numWidth int
+	column   int
+	color    bool
+}
+
+// sideBySideDiff shows the expected and actual data in two aligned columns,
+// with line numbers. Changed characters within changed lines are marked with
+// `^` on the line below, or highlighted if colors are enabled. Lines longer
+// than the columns are wrapped.
+//
+//	  Expected                       Actual
+//	1 name    value                1 name    value
+//	2 colour  red                | 2 color   red
+//	      ^                               ^
+//	3 size    10                 <
+func sideBySideDiff(actual, expected string, opts diffOptions) string {
+	a := splitLines(expected)
+	b := splitLines(actual)
+
+	lines := len(a)
+	if len(b) > lines {
+		lines = len(b)
+	}
+
+	w := &sideBySideWriter{
+		numWidth: len(strconv.Itoa(lines)),
+		color:    opts.color,
+	}
+	w.column = (opts.terminalWidth() - 2*(w.numWidth+1) - len(sideBySideEqual)) / 2
+	if w.column < minSideBySideColumn {
+		w.column = minSideBySideColumn
+	}
+
+	w.row(sideBySideLine{runes: []rune("Expected")}, sideBySideLine{runes: []rune("Actual")}, sideBySideEqual)
+
+	for _, op := range difflib.NewMatcher(a, b).GetOpCodes() {
+		i, j := op.I1, op.J1
+
+		switch op.Tag {
+		case 'e':
+			for ; i < op.I2; i, j = i+1, j+1 {
+				w.row(newSideBySideLine(i, a[i], nil), newSideBySideLine(j, b[j], nil), sideBySideEqual)
+			}
+
+		case 'r':
+			for ; i < op.I2 && j < op.J2; i, j = i+1, j+1 {
+				left, right := opts.showInvisibles(a[i]), opts.showInvisibles(b[j])
+				expectedMask, actualMask := opts.changedRunes(left, right)
+				w.row(newSideBySideLine(i, left, expectedMask), newSideBySideLine(j, right, actualMask), sideBySideChanged)
+			}
+			fallthrough
+
+		case 'd', 'i':
+			for ; i < op.I2; i++ {
+				left := opts.showInvisibles(a[i])
+				w.row(newSideBySideLine(i, left, allChanged(left)), sideBySideLine{}, sideBySideDeleted)
+			}
+			for ; j < op.J2; j++ {
+				right := opts.showInvisibles(b[j])
+				w.row(sideBySideLine{}, newSideBySideLine(j, right, allChanged(right)), sideBySideAdded)
+			}
+		}
+	}
+
+	return w.buf.String()
+}
+
+// newSideBySideLine returns the line with the index i, expanding tabs.
+func newSideBySideLine(i int, text string, changed []bool) sideBySideLine {
+	line := sideBySideLine{num: i + 1}
+
+	for k, r := range []rune(text) {
+		n := 1
+		if r == '\t' {
+			r, n = ' ', sideBySideTabWidth
+		}
+
+		for ; n > 0; n-- {
+			line.runes = append(line.runes, r)
+			line.changed = append(line.changed, k < len(changed) && changed[k])
+		}
+	}
+
+	return line
+}
+
+// allChanged returns a mask marking every rune of the text as changed.
+func allChanged(text string) []bool {
+	mask := make([]bool, len([]rune(text)))
+	for i := range mask {
+		mask[i] = true
+	}
+
+	return mask
+}
+
+// row writes the lines next to each other, wrapping them to the column width.
+// Changed lines are followed by the markers of the changed characters, unless
+// colors are enabled.
+func (w *sideBySideWriter) row(left, right sideBySideLine, gutter string) {
+	for k := 0; k == 0 || k*w.column < len(left.runes) || k*w.column < len(right.runes); k++ {
+		leftText, leftMarks := w.cell(left, k, ansiRed)
+		rightText, rightMarks := w.cell(right, k, ansiGreen)
+
+		w.buf.WriteString(strings.TrimRight(leftText+gutter+rightText, " "))
+		w.buf.WriteString("\n")
+
+		if gutter == sideBySideChanged && !w.color && strings.TrimSpace(leftMarks+rightMarks) != "" {
+			w.buf.WriteString(strings.TrimRight(leftMarks+sideBySideEqual+rightMarks, " "))
+			w.buf.WriteString("\n")
+		}
+	}
+}
+
+// cell returns the k-th wrapped part of the line, prefixed with the line
+// number on the first part and padded to the column width, along with the
+// markers of its changed characters. With colors enabled, the changed
+// characters are highlighted in the given color instead.
+func (w *sideBySideWriter) cell(line sideBySideLine, k int, color string) (text, marks string) {
+	num := ""
+	if line.num > 0 && k == 0 {
+		num = strconv.Itoa(line.num)
+	}
+
+	var textBuf, marksBuf strings.Builder
+	textBuf.WriteString(strings.Repeat(" ", w.numWidth-len(num)) + num + " ")
+	marksBuf.WriteString(strings.Repeat(" ", w.numWidth+1))
+
+	start, end := k*w.column, (k+1)*w.column
+	if start > len(line.runes) {
+		start = len(line.runes)
+	}
+	if end > len(line.runes) {
+		end = len(line.runes)
+	}
+
+	highlighted := false
+	for i := start; i < end; i++ {
+		changed := i < len(line.changed) && line.changed[i]
+
+		if w.color && changed != highlighted {
+			if changed {
+				textBuf.WriteString(color)
+			} else {
+				textBuf.WriteString(ansiReset)
+			}
+			highlighted = changed
+		}
+		textBuf.WriteRune(line.runes[i])
+
+		if changed {
+			marksBuf.WriteString("^")
+		} else {
+			marksBuf.WriteString(" ")
+		}
+	}
+	if highlighted {
+		textBuf.WriteString(ansiReset)
+	}
+
+	padding := strings.Repeat(" ", w.column-(end-start))
+	textBuf.WriteString(padding)
+	marksBuf.WriteString(padding)
+
+	return textBuf.String(), marksBuf.String()
+}
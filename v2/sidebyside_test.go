@@ -0,0 +1,97 @@
+package goldie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setTerminalWidth makes the detected terminal width the given number of
+// columns for the duration of the test.
+func setTerminalWidth(t *testing.T, columns int) {
+	detect := detectTerminalWidth
+	detectTerminalWidth = func() int { return columns }
+	t.Cleanup(func() { detectTerminalWidth = detect })
+}
+
+func TestSideBySideDiff(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	setTerminalWidth(t, 0)
+
+	tests := map[string]struct {
+		actual   string
+		expected string
+		opts     diffOptions
+		diff     string
+	}{
+		"changed, deleted and added lines": {
+			actual:   "name    value\ncolor   red\nx\nnew line\n",
+			expected: "name    value\ncolour  red\nold line\nx\n",
+			opts:     diffOptions{width: 60},
+			diff: `  Expected                       Actual
+1 name    value                1 name    value
+2 colour  red                | 2 color   red
+      ^                               ^
+3 old line                   <
+4 x                            3 x
+                             > 4 new line
+`,
+		},
+		"tabs": {
+			actual:   "a\tb",
+			expected: "a\tc",
+			opts:     diffOptions{width: 60},
+			diff: `  Expected                       Actual
+1 a    c                     | 1 a    b
+       ^                              ^
+`,
+		},
+		"wrapped": {
+			actual:   "a long line that wraps around the column width for sure",
+			expected: "a long line that wraps around the column width, sure",
+			opts:     diffOptions{width: 40},
+			diff: `  Expected                 Actual
+1 a long line that wra | 1 a long line that wra
+  ps around the column |   ps around the column
+   width, sure         |    width for sure
+        ^                        ^^^^
+`,
+		},
+		"colored": {
+			actual:   "abc\nnew",
+			expected: "abd",
+			opts:     diffOptions{width: 60, color: true},
+			diff: "  Expected                       Actual\n" +
+				"1 ab\x1b[31md\x1b[0m                        | 1 ab\x1b[32mc\x1b[0m\n" +
+				"                             > 2 \x1b[32mnew\x1b[0m\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.diff, sideBySideDiff(test.actual, test.expected, test.opts))
+		})
+	}
+}
+
+func TestSideBySideDiffTerminalWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "50")
+	setTerminalWidth(t, 80)
+
+	g := New(t, WithDiffEngine(SideBySideDiff), WithDiffWidth(200))
+	assert.Equal(t, 50, g.diffOptions.terminalWidth())
+	assert.Equal(t, `  Expected                  Actual
+1 abc                   | 1 abd
+    ^                         ^
+`, g.diffOptions.diff(g.diffEngine, "abd", "abc"))
+
+	// Without COLUMNS, the width of the terminal is used, or else the
+	// configured width.
+	t.Setenv("COLUMNS", "")
+	assert.Equal(t, 80, g.diffOptions.terminalWidth())
+
+	setTerminalWidth(t, 0)
+	assert.Equal(t, 200, g.diffOptions.terminalWidth())
+
+	assert.Error(t, g.WithDiffWidth(0))
+}
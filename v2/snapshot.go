@@ -0,0 +1,319 @@
+package goldie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// snapshotSuffix is the suffix of snapshot files.
+	snapshotSuffix = ".snap"
+
+	// snapshotHeader is written at the top of snapshot files. Anything before
+	// the first entry is ignored when reading them.
+	snapshotHeader = "# Golden snapshots, one entry per fixture. Update with `go test -update`.\n"
+
+	// snapshotMarkerPrefix and snapshotMarkerSuffix enclose the name of an
+	// entry on the line starting it.
+	snapshotMarkerPrefix = "-- "
+	snapshotMarkerSuffix = " --"
+
+	// snapshotEscape is prepended to content lines that would otherwise be
+	// read as the start of an entry, or that start with it themselves.
+	snapshotEscape = `\`
+)
+
+var (
+	// snapshotMu serializes the updates of snapshot files, which may be
+	// shared by parallel tests.
+	snapshotMu sync.Mutex
+
+	// osSnapshot reads and writes the snapshot files found on disk when
+	// handling orphaned entries.
+	osSnapshot = &Goldie{filePerms: defaultFilePerms, dirPerms: defaultDirPerms, fsys: osFS{}}
+)
+
+// SnapshotMode determines whether, and how, fixtures are stored in snapshot
+// files holding many fixtures each, instead of a golden file per fixture.
+type SnapshotMode int
+
+const (
+	// SnapshotOff stores every fixture in its own golden file.
+	SnapshotOff SnapshotMode = iota
+
+	// SnapshotPerTest stores the fixtures of each top-level test in one
+	// snapshot file named after the test, e.g. `testdata/TestRender.snap`.
+	// The entries are keyed by the sub-test name and the fixture name.
+	SnapshotPerTest
+
+	// SnapshotPerFile stores the fixtures of all tests in one snapshot file
+	// named with WithSnapshotFile, e.g. `testdata/render.snap`. The entries
+	// are keyed by the test name and the fixture name.
+	SnapshotPerFile
+)
+
+var (
+	// errSnapshotFileName is returned if SnapshotPerFile is used without
+	// naming the snapshot file.
+	errSnapshotFileName = errors.New("snapshots per file require the snapshot file name, see WithSnapshotFile")
+
+	// errSnapshotReceived is returned if received files are used together
+	// with snapshots.
+	errSnapshotReceived = errors.New("received files are not supported for snapshots")
+
+	// errSnapshotVariants is returned if variants are used together with
+	// snapshots.
+	errSnapshotVariants = errors.New("variants are not supported for snapshots")
+)
+
+// validateSnapshots checks that the options of the tester can be used with
+// its snapshot mode.
+func (g *Goldie) validateSnapshots() error {
+	switch {
+	case g.snapshotMode == SnapshotOff:
+		return nil
+	case g.snapshotMode == SnapshotPerFile && g.snapshotFileName == "":
+		return errSnapshotFileName
+	case g.useReceivedFiles:
+		return errSnapshotReceived
+	case len(g.variants) > 0:
+		return errSnapshotVariants
+	}
+
+	return nil
+}
+
+// snapshotFile returns the name of the snapshot file holding the fixtures of
+// the test. Like for golden files, an error is returned if the name is
+// rejected by the name policy, or if the snapshot file is outside of the
+// fixture directory. The name is returned in any case.
+func (g *Goldie) snapshotFile(t TB) (string, error) {
+	parts := []string{strings.Split(t.Name(), "/")[0]}
+	if g.snapshotMode == SnapshotPerFile {
+		parts = strings.Split(g.snapshotFileName, "/")
+	}
+
+	return g.fixturePath(t, parts, snapshotSuffix)
+}
+
+// snapshotKey returns the name of the snapshot entry of the fixture.
+func (g *Goldie) snapshotKey(t TB, name string) string {
+	parts := strings.Split(t.Name(), "/")
+	if g.snapshotMode == SnapshotPerTest {
+		parts = parts[1:]
+	}
+
+	return strings.Join(append(parts, name), "/")
+}
+
+// fixtureKey identifies the fixture within the test run. That's the golden
+// file or, for snapshots, the snapshot file and the entry.
+func (g *Goldie) fixtureKey(t TB, name string) string {
+	goldenFile := g.GoldenFileName(t, name)
+	if g.snapshotMode == SnapshotOff {
+		return goldenFile
+	}
+
+	return goldenFile + "#" + g.snapshotKey(t, name)
+}
+
+// readSnapshot reads the expected data of the fixture from its entry in the
+// snapshot file. A missing entry is reported as fs.ErrNotExist.
+func (g *Goldie) readSnapshot(t TB, snapshotFile, name string) ([]byte, error) {
+	key := g.snapshotKey(t, name)
+	used.trackSnapshot(g, snapshotFile, key)
+
+	entries, err := g.loadSnapshot(snapshotFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := entries[key]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: snapshotFile + "#" + key, Err: fs.ErrNotExist}
+	}
+
+	return data, nil
+}
+
+// updateSnapshot updates the entry of the fixture in the snapshot file with
+// the actual data. The snapshot file is only written if the entry changed.
+// When cleaning, the snapshot file is removed along with the rest of the
+// fixture directory before its first entry is written.
+func (g *Goldie) updateSnapshot(t TB, name string, actualData []byte) error {
+	snapshotFile, err := g.snapshotFile(t)
+	if err != nil {
+		return err
+	}
+	key := g.snapshotKey(t, name)
+	used.trackSnapshot(g, snapshotFile, key)
+
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+
+	// Read the snapshot file before ensureDir, which may clean its
+	// directory, to tell whether the update changed the entry.
+	previousEntries, err := g.loadSnapshot(snapshotFile)
+	if err != nil {
+		return err
+	}
+
+	if err := g.ensureDir(filepath.Dir(snapshotFile)); err != nil {
+		return err
+	}
+
+	entries, err := g.loadSnapshot(snapshotFile)
+	if err != nil {
+		return err
+	}
+
+	id := snapshotFile + "#" + key
+	previous, existed := previousEntries[key]
+
+	kind := updateChanged
+	switch {
+	case !existed:
+		kind = updateCreated
+	case bytes.Equal(previous, actualData):
+		kind = updateUnchanged
+	}
+
+	if current, ok := entries[key]; ok && bytes.Equal(current, actualData) {
+		used.updated(id, kind)
+		return nil
+	}
+
+	entries[key] = actualData
+	if err := g.writeSnapshot(snapshotFile, entries); err != nil {
+		return err
+	}
+
+	used.updated(id, kind)
+	return nil
+}
+
+// loadSnapshot reads and decodes the snapshot file. A missing snapshot file
+// has no entries.
+func (g *Goldie) loadSnapshot(snapshotFile string) (map[string][]byte, error) {
+	data, err := g.readFile(snapshotFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string][]byte{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := decodeSnapshot(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", snapshotFile, err)
+	}
+
+	return entries, nil
+}
+
+// writeSnapshot encodes and writes the entries to the snapshot file.
+func (g *Goldie) writeSnapshot(snapshotFile string, entries map[string][]byte) error {
+	wfs, err := g.writableFS()
+	if err != nil {
+		return err
+	}
+
+	if err := wfs.MkdirAll(filepath.ToSlash(filepath.Dir(snapshotFile)), g.dirPerms); err != nil {
+		return err
+	}
+
+	data, err := encodeSnapshot(entries)
+	if err != nil {
+		return err
+	}
+
+	return wfs.WriteFile(filepath.ToSlash(snapshotFile), data, g.filePerms)
+}
+
+// encodeSnapshot encodes the entries, sorted by name. Each entry starts with
+// a `-- name --` line, followed by the data and a newline. Lines of the data
+// that start with `-- ` or `\` are escaped with a leading `\`.
+func encodeSnapshot(entries map[string][]byte) ([]byte, error) {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		if strings.ContainsAny(name, "\r\n") {
+			return nil, fmt.Errorf("invalid snapshot entry name %q: must not contain line breaks", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString(snapshotHeader)
+
+	for _, name := range names {
+		buf.WriteString("\n" + snapshotMarkerPrefix + name + snapshotMarkerSuffix + "\n")
+
+		for i, line := range bytes.SplitAfter(entries[name], []byte("\n")) {
+			if len(line) == 0 && i > 0 {
+				continue
+			}
+			if bytes.HasPrefix(line, []byte(snapshotMarkerPrefix)) || bytes.HasPrefix(line, []byte(snapshotEscape)) {
+				buf.WriteString(snapshotEscape)
+			}
+			buf.Write(line)
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeSnapshot decodes the entries of a snapshot file written by
+// encodeSnapshot.
+func decodeSnapshot(data []byte) (map[string][]byte, error) {
+	entries := map[string][]byte{}
+
+	var (
+		name string
+		body []byte
+		open bool
+	)
+	closeEntry := func(last bool) {
+		if !open {
+			return
+		}
+		// Drop the newline terminating the data and, unless it's the last
+		// entry, the blank line separating it from the next entry.
+		body = bytes.TrimSuffix(body, []byte("\n"))
+		if !last {
+			body = bytes.TrimSuffix(body, []byte("\n"))
+		}
+		entries[name] = body
+	}
+
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		text := strings.TrimRight(string(line), "\r\n")
+		if strings.HasPrefix(text, snapshotMarkerPrefix) && strings.HasSuffix(text, snapshotMarkerSuffix) &&
+			len(text) >= len(snapshotMarkerPrefix)+len(snapshotMarkerSuffix) {
+			closeEntry(false)
+
+			name = text[len(snapshotMarkerPrefix) : len(text)-len(snapshotMarkerSuffix)]
+			if _, ok := entries[name]; ok {
+				return nil, fmt.Errorf("duplicate snapshot entry %q", name)
+			}
+			body, open = []byte{}, true
+			continue
+		}
+
+		if !open {
+			continue
+		}
+
+		body = append(body, bytes.TrimPrefix(line, []byte(snapshotEscape))...)
+	}
+	closeEntry(true)
+
+	return entries, nil
+}
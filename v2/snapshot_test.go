@@ -0,0 +1,207 @@
+package goldie
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotEncoding(t *testing.T) {
+	entries := map[string][]byte{
+		"b/escaped":    []byte("-- not a marker --\n\\backslash\nplain"),
+		"a/newline":    []byte("line\n"),
+		"c/empty":      {},
+		"d/blank-line": []byte("\n\n"),
+	}
+
+	data, err := encodeSnapshot(entries)
+	require.NoError(t, err)
+	assert.Equal(t, snapshotHeader+
+		"\n-- a/newline --\nline\n\n"+
+		"\n-- b/escaped --\n\\-- not a marker --\n\\\\backslash\nplain\n"+
+		"\n-- c/empty --\n\n"+
+		"\n-- d/blank-line --\n\n\n\n", string(data))
+
+	decoded, err := decodeSnapshot(data)
+	require.NoError(t, err)
+	assert.Equal(t, entries, decoded)
+
+	_, err = encodeSnapshot(map[string][]byte{"a\nb": nil})
+	assert.Error(t, err)
+
+	_, err = decodeSnapshot([]byte("-- a --\n\n-- a --\n"))
+	assert.Error(t, err)
+}
+
+func TestSnapshotPerTest(t *testing.T) {
+	savedUpdateState := *update
+	t.Cleanup(func() {
+		*update = savedUpdateState
+	})
+
+	dir := t.TempDir()
+	g := New(t, WithFixtureDir(dir), WithSnapshots(SnapshotPerTest))
+	snapshotFile := filepath.Join(dir, "TestSnapshotPerTest.snap")
+	assert.Equal(t, snapshotFile, g.GoldenFileName(t, "example"))
+
+	*update = true
+	g.Assert(t, "example", []byte("abc"))
+	t.Run("sub", func(t *testing.T) {
+		g.Assert(t, "example", []byte("def\n"))
+	})
+	*update = savedUpdateState
+
+	assertFileContent(t, snapshotFile, snapshotHeader+
+		"\n-- example --\nabc\n"+
+		"\n-- sub/example --\ndef\n\n")
+
+	result, err := g.Check(t, "example", []byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusMatch, result.Status)
+	assert.Equal(t, snapshotFile, result.GoldenFile)
+
+	result, err = g.Check(t, "missing", []byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusMissing, result.Status)
+
+	ft := &fakeTB{name: t.Name()}
+	g.Assert(ft, "example", []byte("abd"))
+	assert.NotEmpty(t, ft.errors)
+}
+
+func TestSnapshotPerFile(t *testing.T) {
+	savedUpdateState := *update
+	t.Cleanup(func() {
+		*update = savedUpdateState
+	})
+
+	dir := t.TempDir()
+	g := New(t, WithFixtureDir(dir), WithSnapshotFile("render"))
+	snapshotFile := filepath.Join(dir, "render.snap")
+
+	*update = true
+	g.Assert(t, "example", []byte("abc"))
+	*update = savedUpdateState
+
+	assertFileContent(t, snapshotFile, snapshotHeader+
+		"\n-- TestSnapshotPerFile/example --\nabc\n")
+}
+
+func TestSnapshotOptions(t *testing.T) {
+	for name, options := range map[string][]Option{
+		"no snapshot file": {WithSnapshots(SnapshotPerFile)},
+		"received files":   {WithSnapshots(SnapshotPerTest), WithReceivedFiles(true)},
+		"variants":         {WithVariants(GOOS), WithSnapshotFile("render")},
+	} {
+		ft := &fakeTB{name: t.Name()}
+		New(ft, options...)
+		assert.True(t, ft.failed, name)
+		assert.Len(t, ft.errors, 1, name)
+	}
+
+	savedReceived := *received
+	t.Cleanup(func() {
+		*received = savedReceived
+	})
+
+	g := New(t, WithFixtureDir(t.TempDir()), WithSnapshots(SnapshotPerTest))
+	*received = true
+	_, err := g.Check(t, "example", []byte("abc"))
+	*received = savedReceived
+	assert.True(t, errors.Is(err, errSnapshotReceived))
+}
+
+func TestSnapshotClean(t *testing.T) {
+	savedUpdateState, savedCleanState := *update, *clean
+	t.Cleanup(func() {
+		*update, *clean = savedUpdateState, savedCleanState
+	})
+
+	dir := t.TempDir()
+	snapshotFile := filepath.Join(dir, "TestSnapshotClean.snap")
+	require.NoError(t, os.WriteFile(snapshotFile, []byte(snapshotHeader+
+		"\n-- example --\nabc\n"+
+		"\n-- removed --\nold\n"), 0644))
+	stale := filepath.Join(dir, "stale.golden")
+	require.NoError(t, os.WriteFile(stale, nil, 0644))
+
+	g := New(t, WithFixtureDir(dir), WithSnapshots(SnapshotPerTest))
+	*update, *clean = true, true
+	g.Assert(t, "example", []byte("abc"))
+	g.Assert(t, "added", []byte("def"))
+	*update, *clean = savedUpdateState, savedCleanState
+
+	assertNoFile(t, stale)
+	assertFileContent(t, snapshotFile, snapshotHeader+
+		"\n-- added --\ndef\n"+
+		"\n-- example --\nabc\n")
+}
+
+func TestSnapshotUnchanged(t *testing.T) {
+	fsys := newMemFS()
+	g := New(t, WithFS(fsys), WithSnapshots(SnapshotPerTest))
+	snapshotFile := "testdata/TestSnapshotUnchanged.snap"
+
+	require.NoError(t, g.Update(t, "example", []byte("abc")))
+	require.Contains(t, fsys.MapFS, snapshotFile)
+
+	require.NoError(t, fsys.WriteFile(snapshotFile, []byte(snapshotHeader+"\n-- example --\nabc\n"), 0600))
+
+	// An identical entry leaves the snapshot file untouched.
+	require.NoError(t, g.Update(t, "example", []byte("abc")))
+	assert.Equal(t, os.FileMode(0600), fsys.MapFS[snapshotFile].Mode)
+
+	require.NoError(t, g.Update(t, "example", []byte("abcd")))
+	assert.Equal(t, defaultFilePerms, fsys.MapFS[snapshotFile].Mode)
+}
+
+func TestSnapshotOrphans(t *testing.T) {
+	dir := t.TempDir()
+	snapshotFile := filepath.Join(dir, "TestSnapshotOrphans.snap")
+	require.NoError(t, os.WriteFile(snapshotFile, []byte(snapshotHeader+
+		"\n-- example --\nabc\n"+
+		"\n-- removed --\nold\n"), 0644))
+
+	g := New(t, WithFixtureDir(dir), WithSnapshots(SnapshotPerTest))
+	g.Assert(t, "example", []byte("abc"))
+
+	orphans, err := Orphans()
+	require.NoError(t, err)
+	assert.Contains(t, orphans, snapshotFile+"#removed")
+	assert.NotContains(t, orphans, snapshotFile+"#example")
+	assert.NotContains(t, orphans, snapshotFile)
+}
+
+func TestSnapshotFileName(t *testing.T) {
+	g := New(t)
+	for _, name := range []string{"", ".", "../escaped", "sub/../../escaped", "/abs"} {
+		assert.Error(t, g.WithSnapshotFile(name), name)
+	}
+	require.NoError(t, g.WithSnapshotFile("sub/render"))
+	assert.Equal(t, filepath.Join("testdata", "sub", "render.snap"), g.GoldenFileName(t, "example"))
+
+	dir := t.TempDir()
+	g = New(t, WithFixtureDir(dir), WithSnapshotFile("render"))
+
+	// Names bypassing the option are still rejected when used.
+	g.snapshotFileName = "../escaped"
+	_, err := g.Check(t, "example", []byte("abc"))
+	var escape *FixturePathEscapeError
+	assert.True(t, errors.As(err, &escape), err)
+	assert.True(t, errors.As(g.Update(t, "example", []byte("abc")), &escape))
+	assertNoFile(t, filepath.Join(filepath.Dir(dir), "escaped.snap"))
+
+	var invalid *InvalidFixtureNameError
+	g.namePolicy = NameReject
+	assert.True(t, errors.As(g.Update(t, "example", []byte("abc")), &invalid))
+	assertNoFile(t, filepath.Join(filepath.Dir(dir), "escaped.snap"))
+
+	g = New(t, WithFixtureDir(dir), WithSnapshots(SnapshotPerTest), WithNamePolicy(NameReject))
+	ft := &fakeTB{name: "Test with spaces"}
+	_, err = g.Check(ft, "example", []byte("abc"))
+	assert.True(t, errors.As(err, &invalid), err)
+}
@@ -0,0 +1,9 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package goldie
+
+// terminalColumns returns 0, as the width of the terminal is only detected on
+// Unix-like systems.
+func terminalColumns() int {
+	return 0
+}
@@ -0,0 +1,39 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package goldie
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminalColumns returns the width of the terminal the test runs in, or 0 if
+// there is none. Standard error is asked first. As `go test` usually pipes the
+// output of the test binary, the controlling terminal of the process is asked
+// next.
+func terminalColumns() int {
+	if columns := ttyColumns(syscall.Stderr); columns > 0 {
+		return columns
+	}
+
+	fd, err := syscall.Open("/dev/tty", syscall.O_RDONLY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return 0
+	}
+	defer syscall.Close(fd)
+
+	return ttyColumns(fd)
+}
+
+// ttyColumns returns the width of the terminal of the file descriptor, or 0 if
+// it's not a terminal.
+func ttyColumns(fd int) int {
+	var size struct{ rows, columns, xPixels, yPixels uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd),
+		uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return 0
+	}
+
+	return int(size.columns)
+}
@@ -0,0 +1,130 @@
+package goldie
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/build"
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	// OrphansReport lists orphaned golden files after the test run.
+	OrphansReport = "report"
+
+	// OrphansFail lists orphaned golden files after the test run and fails
+	// the run if there are any.
+	OrphansFail = "fail"
+
+	// OrphansPrune removes orphaned golden files after the test run.
+	OrphansPrune = "prune"
+)
+
+// orphans determines how golden files that no assertion used during the test
+// run are handled by Main; one of OrphansReport, OrphansFail, OrphansPrune
+// or empty to ignore them.
+var orphans = flag.String("orphans", os.Getenv("GOLDIE_ORPHANS"), "Handle golden test file fixtures not used by any test: report, fail or prune")
+
+// Main runs the tests, prints a summary of the updated golden files, if any,
+// and afterwards handles the golden files that no assertion used, according
+// to the `-orphans` flag. It's meant to be called from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(goldie.Main(m))
+//	}
+//
+// Orphaned golden files are only reported, failed on or pruned when all
+// tests of the package ran successfully; i.e. not when tests failed, or when
+// running with `-run`, `-skip` or `-short`, as the unused golden files may
+// still be used by the tests that did not run. For the same reason, they are
+// only reported, but neither failed on nor pruned, when a test using goldie
+// was skipped, or when test files of the package are excluded by build
+// constraints, e.g. tests for other operating systems.
+func Main(m *testing.M) int {
+	code := m.Run()
+
+	if s := Updates(); s.Total() > 0 {
+		fmt.Fprintf(os.Stderr, "goldie: updated golden files: %s\n", s)
+	}
+
+	switch *orphans {
+	case "":
+		return code
+	case OrphansReport, OrphansFail, OrphansPrune:
+	default:
+		fmt.Fprintf(os.Stderr, "goldie: invalid value %q for -orphans\n", *orphans)
+		return 1
+	}
+
+	if code != 0 || testing.Short() || filtered() {
+		fmt.Fprintln(os.Stderr, "goldie: skipping orphaned golden files check as not all tests ran successfully")
+		return code
+	}
+
+	mode := *orphans
+	if mode != OrphansReport && (used.anySkipped() || excludedTests(".")) {
+		fmt.Fprintln(os.Stderr, "goldie: only reporting orphaned golden files as not all tests ran")
+		mode = OrphansReport
+	}
+
+	if mode == OrphansPrune {
+		pruned, err := PruneOrphans()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "goldie: %s\n", err)
+			return 1
+		}
+
+		for _, path := range pruned {
+			fmt.Fprintf(os.Stderr, "goldie: removed orphaned golden file %s\n", path)
+		}
+
+		return code
+	}
+
+	found, err := Orphans()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goldie: %s\n", err)
+		return 1
+	}
+
+	for _, path := range found {
+		fmt.Fprintf(os.Stderr, "goldie: orphaned golden file %s\n", path)
+	}
+
+	if mode == OrphansFail && len(found) > 0 {
+		return 1
+	}
+
+	return code
+}
+
+// excludedTests reports whether any test file of the package in the
+// directory is excluded by build constraints.
+func excludedTests(dir string) bool {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		var noGo *build.NoGoError
+		return !errors.As(err, &noGo)
+	}
+
+	for _, name := range pkg.IgnoredGoFiles {
+		if strings.HasSuffix(name, "_test.go") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filtered reports whether only a subset of the tests was selected to run.
+func filtered() bool {
+	for _, name := range []string{"test.run", "test.skip"} {
+		if f := flag.Lookup(name); f != nil && f.Value.String() != "" {
+			return true
+		}
+	}
+
+	return false
+}
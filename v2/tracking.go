@@ -0,0 +1,426 @@
+package goldie
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// used records the golden files used during the test run.
+var used = newTracker()
+
+// fuzzCorpusDir is the directory of the fuzz corpus within the fixture
+// directory, which never holds golden files.
+const fuzzCorpusDir = "fuzz"
+
+// fixtureLocation is a fixture directory together with the golden file name
+// suffix used in it.
+type fixtureLocation struct {
+	dir    string
+	suffix string
+}
+
+// updateKind describes how updating a golden file changed it. The kinds are
+// ordered, so that the most significant one is kept if a golden file is
+// updated more than once.
+type updateKind int
+
+const (
+	updateUnchanged updateKind = iota
+	updateChanged
+	updateCreated
+)
+
+// assertion is the first assertion of a golden file during the test run.
+type assertion struct {
+	testName string
+	sum      [sha256.Size]byte
+}
+
+// tracker records the golden files read or written by any tester, the
+// fixture directories they live in, how updating changed them, which test
+// asserted them first and whether any test using goldie was skipped.
+type tracker struct {
+	mu         sync.Mutex
+	files      map[string]bool
+	locations  map[fixtureLocation]bool
+	updates    map[string]updateKind
+	assertions map[string]assertion
+	variants   map[string][]Variant
+	snapshots  map[string]map[string]bool
+	watched    map[string]bool
+	skipped    bool
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		files:      map[string]bool{},
+		locations:  map[fixtureLocation]bool{},
+		updates:    map[string]updateKind{},
+		assertions: map[string]assertion{},
+		variants:   map[string][]Variant{},
+		snapshots:  map[string]map[string]bool{},
+		watched:    map[string]bool{},
+	}
+}
+
+// watch records whether the test is skipped, once it finishes. Skipped tests
+// may not have used all of their golden files, which then look orphaned.
+func (tr *tracker) watch(t TB) {
+	w, ok := t.(interface {
+		Cleanup(func())
+		Skipped() bool
+	})
+	if !ok {
+		return
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	name := t.Name()
+	if tr.watched[name] {
+		return
+	}
+	tr.watched[name] = true
+
+	w.Cleanup(func() {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+
+		delete(tr.watched, name)
+		if w.Skipped() {
+			tr.skipped = true
+		}
+	})
+}
+
+// anySkipped reports whether any watched test was skipped.
+func (tr *tracker) anySkipped() bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	return tr.skipped
+}
+
+// track records that the tester used the golden file.
+func (tr *tracker) track(g *Goldie, goldenFile string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.files[filepath.Clean(goldenFile)] = true
+
+	// Orphans are only looked for on disk, so fixture directories of other
+	// file systems are left out.
+	if _, ok := g.fsys.(osFS); ok {
+		tr.locations[fixtureLocation{dir: filepath.Clean(g.fixtureDir), suffix: g.fileNameSuffix}] = true
+	}
+}
+
+// trackSnapshot records that the tester used the entry of the snapshot file.
+func (tr *tracker) trackSnapshot(g *Goldie, snapshotFile, key string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	snapshotFile = filepath.Clean(snapshotFile)
+	tr.files[snapshotFile] = true
+
+	if _, ok := g.fsys.(osFS); ok {
+		tr.locations[fixtureLocation{dir: filepath.Clean(g.fixtureDir), suffix: snapshotSuffix}] = true
+
+		if tr.snapshots[snapshotFile] == nil {
+			tr.snapshots[snapshotFile] = map[string]bool{}
+		}
+		tr.snapshots[snapshotFile][key] = true
+	}
+}
+
+// trackVariants records that all variants of the generic golden file are in
+// use, including those for other environments.
+func (tr *tracker) trackVariants(g *Goldie, goldenFile string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.variants[filepath.Clean(strings.TrimSuffix(goldenFile, g.fileNameSuffix))] = g.variants
+}
+
+// isVariant reports whether the file is a variant of a generic golden file
+// whose variants are in use, i.e. the file name only adds values of the
+// configured variants to the generic one. The name suffix must have been
+// removed already.
+func (tr *tracker) isVariant(name string) bool {
+	for i := strings.LastIndexByte(name, '.'); i > 0; i = strings.LastIndexByte(name[:i], '.') {
+		variants, ok := tr.variants[name[:i]]
+		if ok && variantSuffixRe(variants).MatchString(name[i:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// assert records that the test asserted the golden file with the actual
+// data. If another test has asserted the golden file before with different
+// data, the two tests would overwrite each other when updating, and an error
+// naming both tests is returned. Golden files of different file systems
+// are told apart.
+func (tr *tracker) assert(fsys fs.FS, goldenFile, testName string, actualData []byte) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	key := fsysID(fsys) + filepath.Clean(goldenFile)
+	sum := sha256.Sum256(actualData)
+
+	// A test may assert the same golden file repeatedly, e.g. to check that
+	// a fix makes it match again.
+	prev, ok := tr.assertions[key]
+	if ok && prev.testName != testName && prev.sum != sum {
+		return newErrDuplicateGoldenFile(goldenFile, testName, prev.testName)
+	}
+
+	tr.assertions[key] = assertion{testName: testName, sum: sum}
+	return nil
+}
+
+// updated records how updating changed the golden file.
+func (tr *tracker) updated(goldenFile string, kind updateKind) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	goldenFile = filepath.Clean(goldenFile)
+	if prev, ok := tr.updates[goldenFile]; !ok || kind > prev {
+		tr.updates[goldenFile] = kind
+	}
+}
+
+// summary counts the updated golden files by how they were changed.
+func (tr *tracker) summary() UpdateSummary {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	var s UpdateSummary
+	for _, kind := range tr.updates {
+		switch kind {
+		case updateCreated:
+			s.Created++
+		case updateChanged:
+			s.Changed++
+		default:
+			s.Unchanged++
+		}
+	}
+
+	return s
+}
+
+// usedFiles returns the sorted golden files used so far.
+func (tr *tracker) usedFiles() []string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	files := make([]string, 0, len(tr.files))
+	for file := range tr.files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	return files
+}
+
+// orphans returns the sorted golden files found in the fixture directories
+// used so far, which have not been used themselves. Fixture directories used
+// without a name suffix are left out, as any file in them would be taken for
+// a golden file, and so is the fuzz corpus.
+func (tr *tracker) orphans() ([]string, error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	found := map[string]bool{}
+	for loc := range tr.locations {
+		if loc.suffix == "" {
+			continue
+		}
+
+		corpus := filepath.Join(loc.dir, fuzzCorpusDir)
+		err := filepath.Walk(loc.dir, func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() && path == corpus {
+				return filepath.SkipDir
+			}
+
+			if info.IsDir() || !strings.HasSuffix(path, loc.suffix) || strings.HasSuffix(path, receivedSuffix) {
+				return nil
+			}
+
+			if !tr.files[filepath.Clean(path)] && !tr.isVariant(filepath.Clean(strings.TrimSuffix(path, loc.suffix))) {
+				found[path] = true
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	orphans := make([]string, 0, len(found))
+	for path := range found {
+		orphans = append(orphans, path)
+	}
+	sort.Strings(orphans)
+
+	return orphans, nil
+}
+
+// orphanEntries returns the entries of the snapshot files used so far, which
+// have not been used themselves, by snapshot file.
+func (tr *tracker) orphanEntries() (map[string][]string, error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	found := map[string][]string{}
+	for snapshotFile, keys := range tr.snapshots {
+		entries, err := osSnapshot.loadSnapshot(snapshotFile)
+		if err != nil {
+			return nil, err
+		}
+
+		for key := range entries {
+			if !keys[key] {
+				found[snapshotFile] = append(found[snapshotFile], key)
+			}
+		}
+		sort.Strings(found[snapshotFile])
+	}
+
+	return found, nil
+}
+
+// UsedGoldenFiles returns the golden files read or written by any tester
+// during the test run so far.
+func UsedGoldenFiles() []string {
+	return used.usedFiles()
+}
+
+// Orphans returns the golden files which are stored in any of the fixture
+// directories used during the test run so far, but which were not read or
+// written by any assertion. Unused entries of the snapshot files used are
+// returned as the snapshot file and the entry name, separated by `#`. Only
+// files ending in the name suffix of the tester are considered, so fixture
+// directories used with an empty suffix are left out, and so is the fuzz
+// corpus in the `fuzz` directory.
+//
+// The result is only meaningful once all tests of the package have run, see
+// Main.
+func Orphans() ([]string, error) {
+	orphans, err := used.orphans()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := used.orphanEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	for snapshotFile, keys := range entries {
+		for _, key := range keys {
+			orphans = append(orphans, snapshotFile+"#"+key)
+		}
+	}
+	sort.Strings(orphans)
+
+	return orphans, nil
+}
+
+// PruneOrphans removes the golden files and snapshot entries returned by
+// Orphans, and returns their names. Only files in fixture directories used
+// during the test run are ever removed.
+//
+// Golden files of tests that did not run, e.g. because they were skipped or
+// excluded by build constraints, look orphaned as well. Main therefore only
+// reports orphans in that case; callers of PruneOrphans have to check
+// themselves.
+func PruneOrphans() ([]string, error) {
+	orphans, err := used.orphans()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range orphans {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := used.orphanEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+
+	for snapshotFile, keys := range entries {
+		if len(keys) == 0 {
+			continue
+		}
+
+		data, err := osSnapshot.loadSnapshot(snapshotFile)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			delete(data, key)
+			orphans = append(orphans, snapshotFile+"#"+key)
+		}
+
+		if err := osSnapshot.writeSnapshot(snapshotFile, data); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(orphans)
+
+	return orphans, nil
+}
+
+// UpdateSummary counts the golden files updated during the test run by how
+// they were changed.
+type UpdateSummary struct {
+	// Created is the number of golden files that did not exist before.
+	Created int
+
+	// Changed is the number of golden files written with new content.
+	Changed int
+
+	// Unchanged is the number of golden files that already had the actual
+	// content, and thus were not written.
+	Unchanged int
+}
+
+// Total returns the number of updated golden files.
+func (s UpdateSummary) Total() int {
+	return s.Created + s.Changed + s.Unchanged
+}
+
+func (s UpdateSummary) String() string {
+	return fmt.Sprintf("%d created, %d changed, %d unchanged", s.Created, s.Changed, s.Unchanged)
+}
+
+// Updates returns the summary of the golden files updated during the test
+// run so far.
+func Updates() UpdateSummary {
+	return used.summary()
+}
@@ -0,0 +1,150 @@
+package goldie
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerOrphans(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.golden", "b.golden", "c.received", "sub/d.golden", "e.txt"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+
+	g := New(t, WithFixtureDir(dir))
+	tr := newTracker()
+	tr.track(g, filepath.Join(dir, "a.golden"))
+	tr.track(g, filepath.Join(dir, "sub", "..", "sub", "d.golden"))
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.golden"),
+		filepath.Join(dir, "sub", "d.golden"),
+	}, tr.usedFiles())
+
+	orphans, err := tr.orphans()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "b.golden")}, orphans)
+}
+
+func TestTrackerOrphansSuffix(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.golden", "b.txt", "fuzz/FuzzExample/seed.golden"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+
+	tr := newTracker()
+	tr.track(New(t, WithFixtureDir(dir), WithNameSuffix("")), filepath.Join(dir, "c"))
+	tr.track(New(t, WithFixtureDir(dir)), filepath.Join(dir, "d.golden"))
+
+	orphans, err := tr.orphans()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.golden")}, orphans)
+}
+
+func TestTrackerSkipped(t *testing.T) {
+	tr := newTracker()
+
+	t.Run("run", func(t *testing.T) {
+		tr.watch(t)
+	})
+	assert.False(t, tr.anySkipped())
+
+	t.Run("skipped", func(t *testing.T) {
+		tr.watch(t)
+		t.Skip("not supported")
+	})
+	assert.True(t, tr.anySkipped())
+}
+
+func TestExcludedTests(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a_test.go"), []byte("package a\n"), 0644))
+	assert.False(t, excludedTests(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b_test.go"), []byte("//go:build ignore\n\npackage a\n"), 0644))
+	assert.True(t, excludedTests(dir))
+}
+
+func TestTrackerMissingFixtureDir(t *testing.T) {
+	g := New(t, WithFixtureDir(filepath.Join(t.TempDir(), "missing")))
+	tr := newTracker()
+	tr.track(g, g.GoldenFileName(t, "example"))
+
+	orphans, err := tr.orphans()
+	require.NoError(t, err)
+	assert.Empty(t, orphans)
+}
+
+func TestAssertTracksGoldenFiles(t *testing.T) {
+	g := New(t, WithFixtureDir(t.TempDir()))
+
+	_, err := g.Check(t, "tracked", []byte("abc"))
+	require.NoError(t, err)
+	assert.Contains(t, UsedGoldenFiles(), g.GoldenFileName(t, "tracked"))
+}
+
+func TestTrackerSummary(t *testing.T) {
+	tr := newTracker()
+	tr.updated("a.golden", updateCreated)
+	tr.updated("a.golden", updateUnchanged)
+	tr.updated("b.golden", updateUnchanged)
+	tr.updated("./b.golden", updateChanged)
+	tr.updated("c.golden", updateUnchanged)
+
+	s := tr.summary()
+	assert.Equal(t, UpdateSummary{Created: 1, Changed: 1, Unchanged: 1}, s)
+	assert.Equal(t, 3, s.Total())
+	assert.Equal(t, "1 created, 1 changed, 1 unchanged", s.String())
+}
+
+func TestTrackerAssert(t *testing.T) {
+	tr := newTracker()
+	require.NoError(t, tr.assert(osFS{}, "testdata/a.golden", "TestA", []byte("abc")))
+	require.NoError(t, tr.assert(osFS{}, "testdata/a.golden", "TestA", []byte("abcd")))
+	require.NoError(t, tr.assert(osFS{}, "testdata/a.golden", "TestB", []byte("abcd")))
+	require.NoError(t, tr.assert(osFS{}, "testdata/b.golden", "TestB", []byte("abc")))
+
+	err := tr.assert(osFS{}, "./testdata/a.golden", "TestC", []byte("abc"))
+	var e *DuplicateGoldenFileError
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "TestC", e.TestName)
+	assert.Equal(t, "TestB", e.PreviousTestName)
+}
+
+func TestTrackerAssertFS(t *testing.T) {
+	tr := newTracker()
+	a, b := fstest.MapFS{}, fstest.MapFS{}
+	require.NoError(t, tr.assert(a, "testdata/a.golden", "TestA", []byte("abc")))
+	require.NoError(t, tr.assert(b, "testdata/a.golden", "TestB", []byte("abcd")))
+	require.NoError(t, tr.assert(osFS{}, "testdata/a.golden", "TestC", []byte("abcde")))
+	assert.Error(t, tr.assert(a, "testdata/a.golden", "TestD", []byte("abcd")))
+
+	// File systems without reference semantics are told apart by value.
+	dirA, dirB := os.DirFS(t.TempDir()), os.DirFS(t.TempDir())
+	require.NoError(t, tr.assert(dirA, "testdata/b.golden", "TestA", []byte("abc")))
+	require.NoError(t, tr.assert(dirB, "testdata/b.golden", "TestB", []byte("abcd")))
+	assert.Error(t, tr.assert(dirA, "testdata/b.golden", "TestC", []byte("abcd")))
+}
+
+func TestAssertDuplicateGoldenFile(t *testing.T) {
+	g := New(t, WithFixtureDir(t.TempDir()))
+
+	a := &fakeTB{name: "TestDuplicate/a"}
+	g.Assert(a, "shared", []byte("abc"))
+
+	b := &fakeTB{name: "TestDuplicate/b"}
+	g.Assert(b, "shared", []byte("abcd"))
+	require.Len(t, b.errors, 1)
+	assert.True(t, b.failed)
+	assert.Contains(t, b.errors[0], "TestDuplicate/a and TestDuplicate/b")
+}
@@ -0,0 +1,95 @@
+package goldie
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// updatePattern restricts updating to the golden files whose test or fixture
+// name matches it. If it's nil, all golden files are updated.
+var updatePattern *regexp.Regexp
+
+// updatePatternErr holds the error of an invalid GOLDIE_UPDATE_PATTERN, which
+// fails every assertion until a valid `-update` flag is given.
+var updatePatternErr error
+
+// updateValue is the flag.Value behind the `-update` flag. It's a boolean
+// flag, so `-update` alone updates all golden files, but it also accepts a
+// regular expression, e.g. `-update=TestRender/.*dark`, that restricts the
+// update to the matching tests and fixtures.
+type updateValue bool
+
+// updateFlag defines the `-update` flag and returns a pointer to its boolean
+// value. The update pattern is read from GOLDIE_UPDATE_PATTERN.
+func updateFlag(name string, value bool, usage string) *bool {
+	v := (*updateValue)(&value)
+	v.setPattern(os.Getenv("GOLDIE_UPDATE_PATTERN"))
+
+	flag.Var(v, name, usage)
+	return &value
+}
+
+// setPattern sets the value from the GOLDIE_UPDATE_PATTERN environment
+// variable, if set. As this happens while the test binary is initialized, an
+// invalid pattern is not reported right away, but by the assertions.
+func (v *updateValue) setPattern(pattern string) {
+	if pattern == "" {
+		return
+	}
+
+	if err := v.Set(pattern); err != nil {
+		updatePatternErr = fmt.Errorf("invalid GOLDIE_UPDATE_PATTERN %q: %w", pattern, err)
+	}
+}
+
+func (v *updateValue) IsBoolFlag() bool {
+	return true
+}
+
+func (v *updateValue) String() string {
+	if v == nil || !*v {
+		return "false"
+	}
+	if updatePattern != nil {
+		return updatePattern.String()
+	}
+	return "true"
+}
+
+// Set parses the flag value. Values that parse as a boolean toggle updating
+// of all golden files; anything else is compiled as the update pattern.
+func (v *updateValue) Set(s string) error {
+	if b, err := strconv.ParseBool(s); err == nil {
+		*v = updateValue(b)
+		updatePattern, updatePatternErr = nil, nil
+		return nil
+	}
+
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+
+	*v = true
+	updatePattern, updatePatternErr = re, nil
+	return nil
+}
+
+// shouldUpdate reports whether the golden file of the named fixture should be
+// updated. With an update pattern, the fixture is only updated if the pattern
+// matches the test name, the fixture name or both joined by a slash.
+func (g *Goldie) shouldUpdate(t TB, name string) bool {
+	if !*update {
+		return false
+	}
+	if updatePattern == nil {
+		return true
+	}
+
+	return updatePattern.MatchString(t.Name()) ||
+		updatePattern.MatchString(name) ||
+		updatePattern.MatchString(t.Name()+"/"+name)
+}
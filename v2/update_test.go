@@ -0,0 +1,117 @@
+package goldie
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateValueSet(t *testing.T) {
+	savedUpdate, savedPattern := *update, updatePattern
+	t.Cleanup(func() {
+		*update, updatePattern = savedUpdate, savedPattern
+	})
+
+	v := (*updateValue)(update)
+
+	require.NoError(t, v.Set("true"))
+	assert.True(t, *update)
+	assert.Nil(t, updatePattern)
+	assert.Equal(t, "true", v.String())
+
+	require.NoError(t, v.Set("TestRender/.*dark"))
+	assert.True(t, *update)
+	require.NotNil(t, updatePattern)
+	assert.Equal(t, "TestRender/.*dark", v.String())
+
+	require.NoError(t, v.Set("false"))
+	assert.False(t, *update)
+	assert.Nil(t, updatePattern)
+	assert.Equal(t, "false", v.String())
+
+	assert.Error(t, v.Set("Test("))
+}
+
+func TestInvalidUpdatePattern(t *testing.T) {
+	savedUpdate, savedPattern := *update, updatePattern
+	t.Cleanup(func() {
+		*update, updatePattern, updatePatternErr = savedUpdate, savedPattern, nil
+	})
+
+	(*updateValue)(update).setPattern("Test(")
+	require.Error(t, updatePatternErr)
+
+	g := New(t, WithFixtureDir(t.TempDir()))
+	ft := &fakeTB{name: t.Name()}
+	g.Assert(ft, "example", []byte("abc"))
+	assert.True(t, ft.failed)
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "invalid GOLDIE_UPDATE_PATTERN")
+
+	require.NoError(t, (*updateValue)(update).Set("false"))
+	assert.NoError(t, updatePatternErr)
+}
+
+func TestSelectiveUpdate(t *testing.T) {
+	savedUpdate, savedPattern := *update, updatePattern
+	t.Cleanup(func() {
+		*update, updatePattern = savedUpdate, savedPattern
+	})
+
+	tests := []struct {
+		pattern string
+		name    string
+		updated bool
+	}{
+		{pattern: "TestSelectiveUpdate", name: "light", updated: true},
+		{pattern: "dark$", name: "dark", updated: true},
+		{pattern: "dark$", name: "light", updated: false},
+		{pattern: "TestSelectiveUpdate/.*dark", name: "dark", updated: true},
+		{pattern: "TestOther", name: "dark", updated: false},
+	}
+
+	for _, test := range tests {
+		g := New(t, WithFixtureDir(t.TempDir()))
+		require.NoError(t, g.Update(t, test.name, []byte("old")))
+
+		require.NoError(t, (*updateValue)(update).Set(test.pattern))
+		result, err := g.Check(t, test.name, []byte("new"))
+		require.NoError(t, (*updateValue)(update).Set("false"))
+		require.NoError(t, err)
+
+		if test.updated {
+			assert.Equal(t, StatusUpdated, result.Status, test.pattern)
+			assertFileContent(t, result.GoldenFile, "new")
+		} else {
+			assert.Equal(t, StatusMismatch, result.Status, test.pattern)
+			assertFileContent(t, result.GoldenFile, "old")
+		}
+	}
+}
+
+func TestUpdateOnlyWritesChanges(t *testing.T) {
+	g := New(t, WithFixtureDir(t.TempDir()))
+	goldenFile := g.GoldenFileName(t, "example")
+	before := Updates()
+
+	require.NoError(t, g.Update(t, "example", []byte("abc")))
+	assertFileContent(t, goldenFile, "abc")
+
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(goldenFile, old, old))
+
+	require.NoError(t, g.Update(t, "example", []byte("abc")))
+	info, err := os.Stat(goldenFile)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(old))
+
+	require.NoError(t, g.Update(t, "example", []byte("abcd")))
+	assertFileContent(t, goldenFile, "abcd")
+
+	after := Updates()
+	assert.Equal(t, before.Created+1, after.Created)
+	assert.Equal(t, before.Total()+1, after.Total())
+}
@@ -0,0 +1,197 @@
+package goldie
+
+import (
+	"bytes"
+	"math/bits"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// goVersionMinorRe matches the minor Go version in runtime.Version(), also
+// for release candidates and development versions.
+var goVersionMinorRe = regexp.MustCompile(`go1\.\d+`)
+
+// Variant selects a property of the environment, which golden files can be
+// specific to. See WithVariants.
+type Variant int
+
+const (
+	// GOOS makes golden files specific to the operating system, e.g.
+	// `example.linux.golden`.
+	GOOS Variant = iota + 1
+
+	// GOARCH makes golden files specific to the architecture, e.g.
+	// `example.amd64.golden`.
+	GOARCH
+
+	// GoVersionMinor makes golden files specific to the minor Go version,
+	// e.g. `example.go1.22.golden`.
+	GoVersionMinor
+)
+
+// value returns the value of the variant in the current environment, or an
+// empty string if it's unknown.
+func (v Variant) value() string {
+	switch v {
+	case GOOS:
+		return runtime.GOOS
+	case GOARCH:
+		return runtime.GOARCH
+	case GoVersionMinor:
+		return goVersionMinorRe.FindString(runtime.Version())
+	}
+
+	return ""
+}
+
+// knownOS and knownArch list the values of GOOS and GOARCH, as go/build
+// knows them, to tell variants for other environments from other files.
+var (
+	knownOS = []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos",
+		"ios", "js", "linux", "nacl", "netbsd", "openbsd", "plan9", "solaris",
+		"wasip1", "windows", "zos",
+	}
+	knownArch = []string{
+		"386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be",
+		"loong64", "mips", "mipsle", "mips64", "mips64le", "mips64p32",
+		"mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64", "s390",
+		"s390x", "sparc", "sparc64", "wasm",
+	}
+)
+
+// pattern returns a regular expression matching the values of the variant in
+// any environment.
+func (v Variant) pattern() string {
+	var values []string
+	switch v {
+	case GOOS:
+		values = append([]string{runtime.GOOS}, knownOS...)
+	case GOARCH:
+		values = append([]string{runtime.GOARCH}, knownArch...)
+	case GoVersionMinor:
+		return `go1\.\d+`
+	}
+
+	for i, value := range values {
+		values[i] = regexp.QuoteMeta(value)
+	}
+
+	return "(?:" + strings.Join(values, "|") + ")"
+}
+
+// variantSuffixRe returns a regular expression matching the part of a file
+// name that a variant for any environment adds to the generic file name, e.g.
+// `.linux.go1.22`.
+func variantSuffixRe(variants []Variant) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, v := range variants {
+		expr.WriteString(`(?:\.` + v.pattern() + ")?")
+	}
+	expr.WriteString("$")
+
+	return regexp.MustCompile(expr.String())
+}
+
+// variantFileNames returns the candidate file names for the generic golden
+// file, from the most specific variant to the generic golden file itself. For
+// the variants GOOS and GoVersionMinor on Linux with Go 1.22 these are
+// `example.linux.go1.22.golden`, `example.linux.golden`,
+// `example.go1.22.golden` and `example.golden`.
+func (g *Goldie) variantFileNames(goldenFile string) []string {
+	var values []string
+	for _, v := range g.variants {
+		if value := v.value(); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	base := strings.TrimSuffix(goldenFile, g.fileNameSuffix)
+
+	var names []string
+	for size := len(values); size > 0; size-- {
+		for mask := uint(1); mask < 1<<uint(len(values)); mask++ {
+			if bits.OnesCount(mask) != size {
+				continue
+			}
+
+			var name strings.Builder
+			name.WriteString(base)
+			for i, value := range values {
+				if mask&(1<<uint(i)) != 0 {
+					name.WriteString(".")
+					name.WriteString(value)
+				}
+			}
+			name.WriteString(g.fileNameSuffix)
+
+			names = append(names, name.String())
+		}
+	}
+
+	return append(names, goldenFile)
+}
+
+// resolveVariant returns the most specific existing variant of the generic
+// golden file, or the generic golden file if there is none. All variants are
+// recorded as used, so they are never reported as orphans.
+func (g *Goldie) resolveVariant(goldenFile string) string {
+	if len(g.variants) == 0 {
+		return goldenFile
+	}
+	used.trackVariants(g, goldenFile)
+
+	for _, name := range g.variantFileNames(goldenFile) {
+		if _, err := g.stat(name); err == nil {
+			return name
+		}
+	}
+
+	return goldenFile
+}
+
+// variantToUpdate returns the variant of the generic golden file that should
+// be updated with the actual data. That is the variant currently in use, see
+// resolveVariant. With the `-update-variant` flag, it's the most specific
+// variant instead, unless the actual data is identical to the golden file it
+// would otherwise fall back to. In that case the most specific variant is
+// redundant and removed.
+func (g *Goldie) variantToUpdate(goldenFile string, actualData []byte) (string, error) {
+	if len(g.variants) == 0 {
+		return goldenFile, nil
+	}
+	if !*updateVariant {
+		return g.resolveVariant(goldenFile), nil
+	}
+	used.trackVariants(g, goldenFile)
+
+	names := g.variantFileNames(goldenFile)
+	specific := names[0]
+
+	for _, fallback := range names[1:] {
+		data, err := g.readFile(fallback)
+		if err != nil {
+			continue
+		}
+
+		if !bytes.Equal(data, actualData) {
+			return specific, nil
+		}
+
+		wfs, err := g.writableFS()
+		if err != nil {
+			return "", err
+		}
+
+		if err := wfs.RemoveAll(filepath.ToSlash(specific)); err != nil {
+			return "", err
+		}
+
+		return fallback, nil
+	}
+
+	return specific, nil
+}
@@ -0,0 +1,142 @@
+package goldie
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariantValue(t *testing.T) {
+	assert.Equal(t, runtime.GOOS, GOOS.value())
+	assert.Equal(t, runtime.GOARCH, GOARCH.value())
+	assert.True(t, strings.HasPrefix(runtime.Version(), GoVersionMinor.value()) ||
+		strings.Contains(runtime.Version(), "devel"))
+	assert.Regexp(t, `^go1\.\d+$`, GoVersionMinor.value())
+}
+
+func TestVariantFileNames(t *testing.T) {
+	g := New(t, WithVariants(GOOS, GOARCH))
+
+	assert.Equal(t, []string{
+		"testdata/example." + runtime.GOOS + "." + runtime.GOARCH + ".golden",
+		"testdata/example." + runtime.GOOS + ".golden",
+		"testdata/example." + runtime.GOARCH + ".golden",
+		"testdata/example.golden",
+	}, g.variantFileNames("testdata/example.golden"))
+
+	g = New(t)
+	assert.Equal(t, []string{"testdata/example.golden"}, g.variantFileNames("testdata/example.golden"))
+}
+
+func TestVariants(t *testing.T) {
+	savedUpdateState, savedUpdateVariant := *update, *updateVariant
+	t.Cleanup(func() {
+		*update, *updateVariant = savedUpdateState, savedUpdateVariant
+	})
+
+	dir := t.TempDir()
+	g := New(t, WithFixtureDir(dir), WithVariants(GOOS))
+	generic := filepath.Join(dir, "example.golden")
+	specific := filepath.Join(dir, "example."+runtime.GOOS+".golden")
+
+	// Without any golden file, the generic one is written.
+	*update = true
+	g.Assert(t, "example", []byte("generic"))
+	assertFileContent(t, generic, "generic")
+	assertNoFile(t, specific)
+
+	// Differing data rewrites the generic golden file in use.
+	g.Assert(t, "example", []byte("changed"))
+	assertFileContent(t, generic, "changed")
+	assertNoFile(t, specific)
+
+	// Data matching the generic golden file does not create a variant, even
+	// when asked to.
+	*updateVariant = true
+	g.Assert(t, "example", []byte("changed"))
+	assertNoFile(t, specific)
+
+	// Differing data is written to the specific variant only when asked to.
+	ft := &fakeTB{name: t.Name()}
+	g.Assert(ft, "example", []byte("specific"))
+	*update, *updateVariant = savedUpdateState, savedUpdateVariant
+	assert.Empty(t, ft.errors)
+	assertFileContent(t, generic, "changed")
+	assertFileContent(t, specific, "specific")
+
+	// The specific variant takes precedence, and is the one updated.
+	assert.Equal(t, specific, g.GoldenFileName(t, "example"))
+	result, err := g.Check(ft, "example", []byte("specific"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusMatch, result.Status)
+
+	*update = true
+	g.Assert(ft, "example", []byte("updated"))
+	*update = savedUpdateState
+	assertFileContent(t, generic, "changed")
+	assertFileContent(t, specific, "updated")
+
+	// Variants for other environments are not orphans, other files are.
+	other := filepath.Join(dir, "example.plan9.golden")
+	require.NoError(t, os.WriteFile(other, nil, 0644))
+	unrelated := filepath.Join(dir, "example.other.golden")
+	require.NoError(t, os.WriteFile(unrelated, nil, 0644))
+	orphans, err := Orphans()
+	require.NoError(t, err)
+	assert.NotContains(t, orphans, other)
+	assert.Contains(t, orphans, unrelated)
+
+	// Updating with data matching the generic golden file again removes the
+	// redundant variant.
+	*update, *updateVariant = true, true
+	result, err = g.Check(ft, "example", []byte("changed"))
+	*update, *updateVariant = savedUpdateState, savedUpdateVariant
+	require.NoError(t, err)
+	assert.Equal(t, generic, result.GoldenFile)
+	assertNoFile(t, specific)
+}
+
+func TestIsVariant(t *testing.T) {
+	tr := newTracker()
+	tr.variants["testdata/example"] = []Variant{GOOS, GoVersionMinor}
+
+	assert.True(t, tr.isVariant("testdata/example.linux"))
+	assert.True(t, tr.isVariant("testdata/example.windows.go1.21"))
+	assert.True(t, tr.isVariant("testdata/example.go1.22"))
+	assert.False(t, tr.isVariant("testdata/example"))
+	assert.False(t, tr.isVariant("testdata/example.amd64"))
+	assert.False(t, tr.isVariant("testdata/example.go1.22.linux"))
+	assert.False(t, tr.isVariant("testdata/example.old"))
+	assert.False(t, tr.isVariant("testdata/other.linux"))
+}
+
+func TestVariantsPlainUpdate(t *testing.T) {
+	savedUpdateState, savedUpdateVariant := *update, *updateVariant
+	t.Cleanup(func() {
+		*update, *updateVariant = savedUpdateState, savedUpdateVariant
+	})
+
+	dir := t.TempDir()
+	g := New(t, WithFixtureDir(dir), WithVariants(GOOS, GoVersionMinor))
+	generic := filepath.Join(dir, "example.golden")
+	require.NoError(t, os.WriteFile(generic, []byte("generic"), 0644))
+
+	// A plain update rewrites the generic golden file and creates none of
+	// the variants.
+	*update, *updateVariant = true, false
+	result, err := g.Check(t, "example", []byte("changed"))
+	*update = savedUpdateState
+	require.NoError(t, err)
+	assert.Equal(t, StatusUpdated, result.Status)
+	assert.Equal(t, generic, result.GoldenFile)
+	assertFileContent(t, generic, "changed")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,282 @@
+package goldie
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlIndent is the number of spaces nested YAML nodes are indented by.
+const yamlIndent = 2
+
+// yamlValue converts any Go value into a canonical YAML node. The value is
+// converted through encoding/json, so `json` struct tags apply.
+func yamlValue(v interface{}) (*yaml.Node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := decodeJson(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return canonicalYaml(jsonYamlNode(value))
+}
+
+// jsonYamlNode converts a decoded JSON value into a YAML node, keeping
+// numbers as they are written.
+func jsonYamlNode(v interface{}) *yaml.Node {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for key, value := range v {
+			n.Content = append(n.Content, jsonYamlNode(key), jsonYamlNode(value))
+		}
+		return n
+
+	case []interface{}:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, value := range v {
+			n.Content = append(n.Content, jsonYamlNode(value))
+		}
+		return n
+
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(string(v), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(v)}
+
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}
+
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+}
+
+// decodeYaml parses all documents of the YAML data into canonical nodes.
+func decodeYaml(data []byte) ([]*yaml.Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+
+	var docs []*yaml.Node
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := canonicalYaml(&doc)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, c)
+	}
+}
+
+// encodeYaml encodes the documents, indenting nested nodes by two spaces.
+func encodeYaml(docs []*yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent)
+
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// canonicalYaml returns a copy of the node in canonical form: aliases are
+// replaced with the nodes they refer to, mapping keys are sorted, block style
+// is used throughout, strings are only quoted if needed and comments are
+// dropped. Duplicate mapping keys are rejected.
+func canonicalYaml(n *yaml.Node) (*yaml.Node, error) {
+	if n.Kind == yaml.AliasNode {
+		return canonicalYaml(n.Alias)
+	}
+
+	c := &yaml.Node{Kind: n.Kind, Tag: n.Tag, Value: n.Value}
+
+	switch n.Kind {
+	case yaml.ScalarNode:
+		// Let the encoder choose the style of strings, which also quotes
+		// strings that YAML 1.1 reads as other types, like `yes`. Encoding
+		// only fails for invalid UTF-8, leaving the node as it is.
+		if n.ShortTag() == "!!str" && n.Style&yaml.TaggedStyle == 0 {
+			_ = c.Encode(n.Value)
+		}
+
+	case yaml.MappingNode:
+		type pair struct {
+			key   string
+			line  int
+			nodes []*yaml.Node
+		}
+
+		pairs := make([]pair, 0, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, err := canonicalYaml(n.Content[i])
+			if err != nil {
+				return nil, err
+			}
+			value, err := canonicalYaml(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, pair{key: yamlKey(key), line: n.Content[i].Line, nodes: []*yaml.Node{key, value}})
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return pairs[i].key < pairs[j].key
+		})
+
+		for i, p := range pairs {
+			if i > 0 && p.key == pairs[i-1].key {
+				return nil, fmt.Errorf("yaml: line %d: mapping key %q already defined at line %d", p.line, p.key, pairs[i-1].line)
+			}
+			c.Content = append(c.Content, p.nodes...)
+		}
+
+	default:
+		for _, child := range n.Content {
+			child, err := canonicalYaml(child)
+			if err != nil {
+				return nil, err
+			}
+			c.Content = append(c.Content, child)
+		}
+	}
+
+	return c, nil
+}
+
+// yamlKey returns the text identifying the mapping key: the value of scalar
+// keys and the YAML encoding of complex keys.
+func yamlKey(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+
+	data, err := yaml.Marshal(n)
+	if err != nil {
+		return n.Value
+	}
+
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// yamlJsonValue converts the node into the values encoding/json decodes to,
+// so that YAML documents can be compared with jsonEqual. Keys of mappings are
+// identified by yamlKey, and merge keys (`<<`) are resolved.
+func yamlJsonValue(n *yaml.Node) interface{} {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return yamlJsonValue(n.Content[0])
+
+	case yaml.SequenceNode:
+		values := make([]interface{}, 0, len(n.Content))
+		for _, child := range n.Content {
+			values = append(values, yamlJsonValue(child))
+		}
+		return values
+
+	case yaml.MappingNode:
+		values := map[string]interface{}{}
+
+		var merged []*yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.ShortTag() == "!!merge" {
+				merged = append(merged, value)
+				continue
+			}
+			values[yamlKey(key)] = yamlJsonValue(value)
+		}
+
+		// Keys of the mapping take precedence over merged keys, and earlier
+		// merged mappings over later ones.
+		for _, value := range merged {
+			sources := []*yaml.Node{value}
+			if value.Kind == yaml.SequenceNode {
+				sources = value.Content
+			}
+			for _, source := range sources {
+				if m, ok := yamlJsonValue(source).(map[string]interface{}); ok {
+					for key, v := range m {
+						if _, ok := values[key]; !ok {
+							values[key] = v
+						}
+					}
+				}
+			}
+		}
+		return values
+
+	case yaml.ScalarNode:
+		var v interface{}
+		if err := n.Decode(&v); err != nil {
+			return n.Value
+		}
+
+		switch v := v.(type) {
+		case int, int64, uint64, float64:
+			return json.Number(fmt.Sprint(v))
+		case time.Time:
+			return v.Format(time.RFC3339Nano)
+		default:
+			return v
+		}
+	}
+
+	return nil
+}
+
+// yamlEqualFn is an EqualFn that compares YAML documents semantically. Data
+// that cannot be decoded is never equal.
+func yamlEqualFn(actual, expected []byte) bool {
+	a, err := decodeYaml(actual)
+	if err != nil {
+		return false
+	}
+
+	e, err := decodeYaml(expected)
+	if err != nil {
+		return false
+	}
+
+	if len(a) != len(e) {
+		return false
+	}
+	for i := range a {
+		if !jsonEqual(yamlJsonValue(a[i]), yamlJsonValue(e[i])) {
+			return false
+		}
+	}
+
+	return true
+}
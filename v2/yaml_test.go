@@ -0,0 +1,248 @@
+package goldie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestEncodeYaml(t *testing.T) {
+	type container struct {
+		Name  string   `json:"name"`
+		Image string   `json:"image"`
+		Args  []string `json:"args,omitempty"`
+	}
+
+	tests := map[string]struct {
+		value    interface{}
+		expected string
+	}{
+		"scalars": {
+			value: map[string]interface{}{
+				"string": "hello",
+				"int":    42,
+				"float":  1.5,
+				"bool":   true,
+				"null":   nil,
+			},
+			expected: "bool: true\nfloat: 1.5\nint: 42\n\"null\": null\nstring: hello\n",
+		},
+		"quoted strings": {
+			value: map[string]interface{}{
+				"number": "42",
+				"octal":  "0777",
+				"bool":   "true",
+				"yes":    "yes",
+				"on":     "on",
+				"empty":  "",
+				"colon":  "a: b",
+				"dash":   "- a",
+			},
+			expected: "bool: \"true\"\ncolon: 'a: b'\ndash: '- a'\nempty: \"\"\nnumber: \"42\"\noctal: \"0777\"\n\"on\": \"on\"\n\"yes\": \"yes\"\n",
+		},
+		"nested": {
+			value: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []container{
+						{Name: "app", Image: "app:1.0", Args: []string{"--verbose"}},
+					},
+					"empty": []string{},
+				},
+			},
+			expected: `spec:
+  containers:
+    - args:
+        - --verbose
+      image: app:1.0
+      name: app
+  empty: []
+`,
+		},
+		"multi-line string": {
+			value: map[string]interface{}{
+				"script": "echo a\necho b\n",
+			},
+			expected: "script: |\n  echo a\n  echo b\n",
+		},
+		"nested sequences": {
+			value:    [][]int{{1, 2}, {3}},
+			expected: "- - 1\n  - 2\n- - 3\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := yamlValue(test.value)
+			require.NoError(t, err)
+
+			actual, err := encodeYaml([]*yaml.Node{v})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(actual))
+
+			docs, err := decodeYaml(actual)
+			require.NoError(t, err)
+			encoded, err := encodeYaml(docs)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(encoded))
+		})
+	}
+}
+
+func TestDecodeYaml(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		expected string
+		err      bool
+	}{
+		"block style": {
+			data: `# a comment
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "example"   # trailing comment
+  labels: {app: web, tier: 'front'}
+data:
+  script: |-
+    #!/bin/sh
+    echo hello
+  folded: >
+    a
+    b
+ports:
+- 80
+- 0x1bb
+`,
+			expected: `apiVersion: v1
+data:
+  folded: |
+    a b
+  script: |-
+    #!/bin/sh
+    echo hello
+kind: ConfigMap
+metadata:
+  labels:
+    app: web
+    tier: front
+  name: example
+ports:
+  - 80
+  - 0x1bb
+`,
+		},
+		"multiple documents": {
+			data:     "---\na: 1\n---\nb: [1, 2]\n",
+			expected: "a: 1\n---\nb:\n  - 1\n  - 2\n",
+		},
+		"escapes": {
+			data:     `a: "tab\there \u00e9"` + "\nb: 'it''s'\n",
+			expected: "a: \"tab\\there é\"\nb: it's\n",
+		},
+		"duplicate keys": {
+			data: "a: 1\na: 2\n",
+			err:  true,
+		},
+		"anchors and aliases": {
+			data:     "base: &base {a: 1}\ncopy: *base\n",
+			expected: "base:\n  a: 1\ncopy:\n  a: 1\n",
+		},
+		"tags": {
+			data:     "a: !!str 12\nb: !custom x\n",
+			expected: "a: \"12\"\nb: !custom x\n",
+		},
+		"multi-line scalars": {
+			data:     "a: plain\n  text\nb: \"double\n  quoted\"\n",
+			expected: "a: plain text\nb: double quoted\n",
+		},
+		"complex keys": {
+			data:     "? [b, c]\n: 2\n? a\n: 1\n",
+			expected: "? - b\n  - c\n: 2\na: 1\n",
+		},
+		"unexpected indentation": {
+			data: "a: 1\n    b: 2\n",
+			err:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			docs, err := decodeYaml([]byte(test.data))
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			actual, err := encodeYaml(docs)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(actual))
+		})
+	}
+}
+
+func TestYamlEqualFn(t *testing.T) {
+	tests := map[string]struct {
+		actual   string
+		expected string
+		equal    bool
+	}{
+		"key order": {
+			actual:   "a: 1\nb: 2\n",
+			expected: "b: 2\na: 1\n",
+			equal:    true,
+		},
+		"quoting style": {
+			actual:   "a: \"x\"\n",
+			expected: "a: 'x'\n",
+			equal:    true,
+		},
+		"indentation": {
+			actual:   "a:\n  - 1\n",
+			expected: "a:\n- 1\n",
+			equal:    true,
+		},
+		"numbers": {
+			actual:   "a: 1.0\n",
+			expected: "a: 1\n",
+			equal:    true,
+		},
+		"string and number": {
+			actual:   "a: \"1\"\n",
+			expected: "a: 1\n",
+			equal:    false,
+		},
+		"octal numbers": {
+			actual:   "a: 0o777\n",
+			expected: "a: 511\n",
+			equal:    true,
+		},
+		"merge keys": {
+			actual:   "base: &base {a: 1, b: 2}\nmerged:\n  <<: *base\n  b: 3\n",
+			expected: "base: {a: 1, b: 2}\nmerged: {a: 1, b: 3}\n",
+			equal:    true,
+		},
+		"timestamps": {
+			actual:   "a: 2001-12-14\n",
+			expected: "a: 2001-12-14T00:00:00Z\n",
+			equal:    true,
+		},
+		"different values": {
+			actual:   "a: 1\n",
+			expected: "a: 2\n",
+			equal:    false,
+		},
+		"invalid": {
+			actual:   "a: [1\n",
+			expected: "a: [1\n",
+			equal:    false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.equal, yamlEqualFn([]byte(test.actual), []byte(test.expected)))
+		})
+	}
+}